@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/keystore"
+)
+
+// Erzeugt eine Identität deren Adresse mit einem gewünschten Base32 Prefix beginnt bzw. mit einem Suffix endet
+// und speichert sie als Keystore Datei. Die Passphrase wird aus OKP2P_KEYSTORE_PASSPHRASE gelesen, ohne
+// Passphrase wird keine Datei geschrieben. Mit -mnemonic werden zusätzlich die 24 Wörter zur Sicherung ausgegeben.
+//
+// Adressen haben den Aufbau "okp2p1" + Version + Schlüsseltyp + Schlüssel + Prüfsumme, Version und Schlüsseltyp
+// sind fest, der Prefix wird daher nach diesen beiden Zeichen gesucht (z.B. okp2p1qq<prefix>...)
+
+type result struct {
+	seed    []byte
+	address string
+}
+
+func main() {
+	prefix := flag.String("prefix", "", "base32 prefix after the fixed address header")
+	suffix := flag.String("suffix", "", "base32 suffix at the end of the address")
+	keyTypeName := flag.String("type", "ed25519", "key type (ed25519, secp256k1, bls12381)")
+	output := flag.String("out", "node.okp2pks", "path of the keystore file to create")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of parallel workers")
+	showMnemonic := flag.Bool("mnemonic", false, "print the 24 backup words of the identity")
+	flag.Parse()
+
+	keyType, err := _ParseKeyType(*keyTypeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	*prefix = strings.ToLower(*prefix)
+	*suffix = strings.ToLower(*suffix)
+	if *prefix == "" && *suffix == "" {
+		log.Fatal("a prefix or suffix is required")
+	}
+	for _, char := range *prefix + *suffix {
+		if !strings.ContainsRune(string(openkeyp2p.Base32DefaultBase32Alphabet), char) {
+			log.Fatalf("invalid character %q, allowed are %q", char, openkeyp2p.Base32DefaultBase32Alphabet)
+		}
+	}
+	if *workers < 1 {
+		log.Fatal("at least one worker is required")
+	}
+
+	// Die Passphrase wird vor der Suche geprüft, damit keine Arbeit verloren geht
+	passphrase := os.Getenv("OKP2P_KEYSTORE_PASSPHRASE")
+	if passphrase == "" {
+		log.Fatal("OKP2P_KEYSTORE_PASSPHRASE is not set, refusing to write an unprotected keystore")
+	}
+
+	// Die Keystore Datei darf nicht vorhanden sein, damit keine Arbeit verloren geht
+	if _, err := os.Stat(*output); err == nil {
+		log.Fatalf("%s: %s", *output, keystore.ErrKeystoreFileExists)
+	}
+
+	// Der feste Teil der Adresse sowie ihre Länge werden aus einer Beispieladresse bestimmt
+	header, addressLength, err := _AddressHeader(keyType)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(header)+len(*prefix)+len(*suffix) > addressLength {
+		log.Fatalf("prefix and suffix are too long, %s addresses have %d characters after the fixed header %q", crypto.KeyTypeName(keyType), addressLength-len(header), header)
+	}
+
+	// Nicht jedes Zeichen ist an jeder Stelle möglich (z.B. Flag Bits komprimierter secp256k1 und BLS Schlüssel),
+	// die möglichen Zeichen je Stelle werden daher anhand zufälliger Adressen bestimmt
+	expectedAttempts, err := _EstimateAttempts(keyType, len(header), *prefix, *suffix)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Searching %s%s...%s with %d workers, expected %.0f attempts\n", header, *prefix, *suffix, *workers, expectedAttempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts atomic.Uint64
+	found := make(chan result, 1)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_Grind(ctx, keyType, header+*prefix, *suffix, &attempts, found)
+		}()
+	}
+
+	// Fortschritt mit Versuchsrate und geschätzter Restzeit
+	start := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var match result
+	for waiting := true; waiting; {
+		select {
+		case match = <-found:
+			waiting = false
+		case <-ticker.C:
+			done := float64(attempts.Load())
+			rate := done / time.Since(start).Seconds()
+			if rate <= 0 {
+				fmt.Printf("\r%.0f attempts   ", done)
+				continue
+			}
+			remaining := time.Duration(math.Max(expectedAttempts-done, 0) / rate * float64(time.Second))
+			fmt.Printf("\r%.0f attempts, %.0f/s, estimated %s remaining   ", done, rate, remaining.Round(time.Second))
+		}
+	}
+	cancel()
+	wg.Wait()
+
+	fmt.Printf("\nFound %s after %d attempts in %s\n", match.address, attempts.Load(), time.Since(start).Round(time.Millisecond))
+
+	if err := keystore.WriteKeystoreFile(*output, keyType, match.seed, []byte(passphrase)); err != nil {
+		log.Fatalf("error writing keystore file: %v", err)
+	}
+	fmt.Printf("Keystore written to %s\n", *output)
+
+	// Die Wörter enthalten den Seed unverschlüsselt, sie werden nur auf ausdrücklichen Wunsch ausgegeben
+	if *showMnemonic {
+		mnemonic, err := crypto.SeedToMnemonic(match.seed)
+		if err != nil {
+			log.Fatalf("error encoding mnemonic: %v", err)
+		}
+		fmt.Printf("Backup words (restore without mnemonic passphrase): %s\n", mnemonic)
+	}
+}
+
+// Erzeugt Seeds bis eine passende Adresse gefunden wurde oder der Kontext beendet wird
+func _Grind(ctx context.Context, keyType openkeyp2p.OpenKeyP2PKeyType, prefix string, suffix string, attempts *atomic.Uint64, found chan<- result) {
+	seed := make([]byte, 32)
+	for ctx.Err() == nil {
+		if _, err := rand.Read(seed); err != nil {
+			log.Fatalf("error generating random seed: %v", err)
+		}
+
+		// Seeds welche keinen gültigen Schlüssel ergeben werden übersprungen
+		signer, err := crypto.NewSignerFromSeed(keyType, seed)
+		if err != nil {
+			continue
+		}
+		addr, err := crypto.OpenKeyP2PAddressFromSigner(signer)
+		if err != nil {
+			continue
+		}
+		attempts.Add(1)
+
+		address := addr.ToString()
+		if strings.HasPrefix(address, prefix) && strings.HasSuffix(address, suffix) {
+			select {
+			case found <- result{seed: append([]byte{}, seed...), address: address}:
+			default:
+			}
+			return
+		}
+	}
+}
+
+// Anzahl der Stichproben zur Bestimmung der möglichen Zeichen je Stelle
+const estimateSamples = 1024
+
+// Schätzt die mittlere Anzahl an Versuchen, enthält das Muster ein Zeichen welches an seiner Stelle
+// in keiner Stichprobe vorkommt, wird ein Fehler zurückgegeben
+func _EstimateAttempts(keyType openkeyp2p.OpenKeyP2PKeyType, headerLength int, prefix string, suffix string) (float64, error) {
+	prefixSets := make([]map[byte]bool, len(prefix))
+	suffixSets := make([]map[byte]bool, len(suffix))
+	for i := range prefixSets {
+		prefixSets[i] = make(map[byte]bool)
+	}
+	for i := range suffixSets {
+		suffixSets[i] = make(map[byte]bool)
+	}
+
+	seed := make([]byte, 32)
+	for samples := 0; samples < estimateSamples; {
+		if _, err := rand.Read(seed); err != nil {
+			return 0, err
+		}
+		signer, err := crypto.NewSignerFromSeed(keyType, seed)
+		if err != nil {
+			continue
+		}
+		addr, err := crypto.OpenKeyP2PAddressFromSigner(signer)
+		if err != nil {
+			continue
+		}
+		address := addr.ToString()
+		for i := range prefixSets {
+			prefixSets[i][address[headerLength+i]] = true
+		}
+		for i := range suffixSets {
+			suffixSets[i][address[len(address)-len(suffix)+i]] = true
+		}
+		samples++
+	}
+
+	reval := 1.0
+	for i, set := range prefixSets {
+		if !set[prefix[i]] {
+			return 0, fmt.Errorf("prefix character %q at position %d is not possible for %s keys", prefix[i], i+1, crypto.KeyTypeName(keyType))
+		}
+		reval *= float64(len(set))
+	}
+	for i, set := range suffixSets {
+		if !set[suffix[i]] {
+			return 0, fmt.Errorf("suffix character %q at position %d is not possible for %s keys", suffix[i], i+1, crypto.KeyTypeName(keyType))
+		}
+		reval *= float64(len(set))
+	}
+	return reval, nil
+}
+
+// Gibt den festen Teil der Adresse ("okp2p1" + Version + Schlüsseltyp) sowie die Länge der Adressen des Schlüsseltyps zurück
+func _AddressHeader(keyType openkeyp2p.OpenKeyP2PKeyType) (string, int, error) {
+	signer, err := crypto.NewSignerFromSeed(keyType, bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		return "", 0, err
+	}
+	addr, err := crypto.OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		return "", 0, err
+	}
+	address := addr.ToString()
+	return address[:len(openkeyp2p.Prefix)+3], len(address), nil
+}
+
+func _ParseKeyType(name string) (openkeyp2p.OpenKeyP2PKeyType, error) {
+	for _, keyType := range []openkeyp2p.OpenKeyP2PKeyType{openkeyp2p.Type_Ed25519, openkeyp2p.Type_Secp256k1, openkeyp2p.Type_BLS12381} {
+		if strings.EqualFold(crypto.KeyTypeName(keyType), name) {
+			return keyType, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key type %q", name)
+}
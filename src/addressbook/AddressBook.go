@@ -0,0 +1,272 @@
+package addressbook
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+)
+
+// Adressbuch der bekannten Peers. Zu jeder Adresse werden die Endpunkte unter denen sie gesehen wurde,
+// der Zeitpunkt der ersten und letzten Beobachtung sowie eine Vertrauensstufe gespeichert:
+//
+//	Pinned:    die Adresse wurde bestätigt, eine andere Adresse unter einem ihrer Endpunkte wird abgelehnt
+//	TOFU:      die Adresse wurde beim ersten Kontakt übernommen, eine andere Adresse erzeugt eine Warnung
+//	Untrusted: Verbindungen mit dieser Adresse werden abgelehnt
+//
+// Endpunkte sind nur bei ausgehenden Verbindungen bekannt, der Port eingehender Verbindungen ist zufällig.
+// Die Zuordnung Endpunkt -> angepinnte Adresse wird daher nur bei ausgehenden Verbindungen durchgesetzt,
+// bei eingehenden Verbindungen wird ausschließlich Untrusted geprüft.
+
+type TrustLevel uint8
+
+const (
+	TrustUntrusted TrustLevel = 1
+	TrustTOFU      TrustLevel = 2
+	TrustPinned    TrustLevel = 3
+)
+
+// Ergebnis einer Beobachtung
+type ObservationResult uint8
+
+const (
+	// Die Adresse war bisher unbekannt und wurde per TOFU übernommen
+	ObservationNew ObservationResult = 1
+	// Die Adresse ist unter dem Endpunkt bereits bekannt
+	ObservationKnown ObservationResult = 2
+	// Unter dem Endpunkt war bisher eine andere, nicht angepinnte Adresse bekannt
+	ObservationChanged ObservationResult = 3
+	// Die Adresse ist unbekannt und wurde ohne Endpunkt gesehen, sie wurde nicht gespeichert
+	ObservationUnrecorded ObservationResult = 4
+)
+
+type AddressBookEntry struct {
+	Address    []byte     `cbor:"1"`
+	TrustLevel TrustLevel `cbor:"2"`
+	Endpoints  []string   `cbor:"3"`
+	FirstSeen  int64      `cbor:"4"`
+	LastSeen   int64      `cbor:"5"`
+}
+
+type AddressBook struct {
+	path    string
+	lock    *sync.Mutex
+	entries map[string]*AddressBookEntry
+}
+
+// Erzeugt ein leeres Adressbuch welches nur im Speicher gehalten wird
+func NewAddressBook() *AddressBook {
+	return &AddressBook{lock: new(sync.Mutex), entries: make(map[string]*AddressBookEntry)}
+}
+
+// Gibt den Namen der Vertrauensstufe zurück
+func (o TrustLevel) String() string {
+	switch o {
+	case TrustUntrusted:
+		return "untrusted"
+	case TrustTOFU:
+		return "tofu"
+	case TrustPinned:
+		return "pinned"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(o))
+	}
+}
+
+// Gibt die Adresse des Eintrags zurück
+func (o *AddressBookEntry) GetAddress() (*crypto.OpenKeyP2PAddress, error) {
+	return crypto.OpenKeyP2PAddressDecodeFromByteSlice(o.Address)
+}
+
+func (o *AddressBookEntry) GetFirstSeen() time.Time {
+	return time.Unix(o.FirstSeen, 0)
+}
+
+func (o *AddressBookEntry) GetLastSeen() time.Time {
+	return time.Unix(o.LastSeen, 0)
+}
+
+// Gibt eine Kopie des Eintrags zurück
+func (o *AddressBook) Get(address *crypto.OpenKeyP2PAddress) (*AddressBookEntry, bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	entry, found := o.entries[address.ToString()]
+	if !found {
+		return nil, false
+	}
+	return _CopyEntry(entry), true
+}
+
+// Gibt Kopien aller Einträge zurück
+func (o *AddressBook) Entries() []*AddressBookEntry {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	reval := make([]*AddressBookEntry, 0, len(o.entries))
+	for _, entry := range o.entries {
+		reval = append(reval, _CopyEntry(entry))
+	}
+	return reval
+}
+
+// Gibt alle Adressen zurück welche unter dem Endpunkt bekannt sind
+func (o *AddressBook) LookupEndpoint(endpoint string) []*crypto.OpenKeyP2PAddress {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	reval := make([]*crypto.OpenKeyP2PAddress, 0)
+	for _, entry := range o.entries {
+		if !slices.Contains(entry.Endpoints, endpoint) {
+			continue
+		}
+		if addr, err := entry.GetAddress(); err == nil {
+			reval = append(reval, addr)
+		}
+	}
+	return reval
+}
+
+// Pinnt eine Adresse, optional zusammen mit einem Endpunkt
+func (o *AddressBook) Pin(address *crypto.OpenKeyP2PAddress, endpoint string) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	entry := o._GetOrCreateEntry(address, time.Now())
+	entry.TrustLevel = TrustPinned
+	if endpoint != "" && !slices.Contains(entry.Endpoints, endpoint) {
+		entry.Endpoints = append(entry.Endpoints, endpoint)
+	}
+	return o._SaveLocked()
+}
+
+// Setzt die Vertrauensstufe einer Adresse, ist die Adresse unbekannt wird sie angelegt
+func (o *AddressBook) SetTrustLevel(address *crypto.OpenKeyP2PAddress, level TrustLevel) error {
+	if level < TrustUntrusted || level > TrustPinned {
+		return fmt.Errorf("%w: %d", ErrInvalidTrustLevel, level)
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o._GetOrCreateEntry(address, time.Now()).TrustLevel = level
+	return o._SaveLocked()
+}
+
+// Entfernt eine Adresse aus dem Adressbuch
+func (o *AddressBook) Remove(address *crypto.OpenKeyP2PAddress) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	delete(o.entries, address.ToString())
+	return o._SaveLocked()
+}
+
+// Prüft eine unter endpoint gesehene Adresse gegen das Adressbuch und speichert die Beobachtung.
+// Ist endpoint leer (z.B. bei eingehenden Verbindungen), wird nur geprüft ob die Adresse Untrusted ist,
+// angepinnte Endpunkte werden nicht durchgesetzt. Unbekannte Adressen ohne Endpunkt werden nicht gespeichert
+// (ObservationUnrecorded), da sie keine Information enthalten.
+// Wird die Adresse abgelehnt, wird ErrUntrustedPeer bzw. ErrPinnedIdentityMismatch zurückgegeben,
+// bei ObservationChanged enthält previous die bisher unter dem Endpunkt bekannten Adressen.
+func (o *AddressBook) Observe(address *crypto.OpenKeyP2PAddress, endpoint string) (result ObservationResult, previous []*crypto.OpenKeyP2PAddress, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	addrKey := address.ToString()
+	entry, found := o.entries[addrKey]
+	if found && entry.TrustLevel == TrustUntrusted {
+		return 0, nil, fmt.Errorf("%w: %s", ErrUntrustedPeer, addrKey)
+	}
+
+	if !found && endpoint == "" {
+		return ObservationUnrecorded, nil, nil
+	}
+
+	// Es wird geprüft ob unter dem Endpunkt eine andere Adresse bekannt ist
+	result = ObservationKnown
+	if !found {
+		result = ObservationNew
+	}
+	if endpoint != "" && (!found || !slices.Contains(entry.Endpoints, endpoint)) {
+		for otherKey, other := range o.entries {
+			if otherKey == addrKey || !slices.Contains(other.Endpoints, endpoint) {
+				continue
+			}
+			otherAddr, decodeErr := other.GetAddress()
+			if decodeErr != nil {
+				continue
+			}
+			if other.TrustLevel == TrustPinned {
+				return 0, nil, fmt.Errorf("%w: endpoint %s is pinned to %s, got %s", ErrPinnedIdentityMismatch, endpoint, otherKey, addrKey)
+			}
+			previous = append(previous, otherAddr)
+		}
+		if len(previous) > 0 {
+			result = ObservationChanged
+		}
+	}
+
+	// Die Beobachtung wird gespeichert, der Endpunkt gehört ab jetzt zur neuen Adresse
+	now := time.Now()
+	for _, prevAddr := range previous {
+		prevEntry := o.entries[prevAddr.ToString()]
+		prevEntry.Endpoints = slices.DeleteFunc(prevEntry.Endpoints, func(item string) bool { return item == endpoint })
+	}
+	entry = o._GetOrCreateEntry(address, now)
+	entry.LastSeen = now.Unix()
+	if endpoint != "" && !slices.Contains(entry.Endpoints, endpoint) {
+		entry.Endpoints = append(entry.Endpoints, endpoint)
+	}
+
+	if saveErr := o._SaveLocked(); saveErr != nil {
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by saving address book {%s}", saveErr)
+	}
+	return result, previous, nil
+}
+
+// Überträgt Vertrauensstufe und Endpunkte nach einem geprüften Identitätswechsel auf die neue Adresse
+func (o *AddressBook) Succeed(oldAddress *crypto.OpenKeyP2PAddress, newAddress *crypto.OpenKeyP2PAddress) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	oldEntry, found := o.entries[oldAddress.ToString()]
+	if !found {
+		return nil
+	}
+
+	newEntry := o._GetOrCreateEntry(newAddress, time.Now())
+	newEntry.TrustLevel = max(newEntry.TrustLevel, oldEntry.TrustLevel)
+	for _, endpoint := range oldEntry.Endpoints {
+		if !slices.Contains(newEntry.Endpoints, endpoint) {
+			newEntry.Endpoints = append(newEntry.Endpoints, endpoint)
+		}
+	}
+	oldEntry.Endpoints = nil
+	return o._SaveLocked()
+}
+
+func (o *AddressBook) _GetOrCreateEntry(address *crypto.OpenKeyP2PAddress, now time.Time) *AddressBookEntry {
+	addrKey := address.ToString()
+	if entry, found := o.entries[addrKey]; found {
+		return entry
+	}
+	entry := &AddressBookEntry{
+		Address:    address.ToByteSlice(),
+		TrustLevel: TrustTOFU,
+		FirstSeen:  now.Unix(),
+		LastSeen:   now.Unix(),
+	}
+	o.entries[addrKey] = entry
+	return entry
+}
+
+func _CopyEntry(entry *AddressBookEntry) *AddressBookEntry {
+	reval := *entry
+	reval.Address = slices.Clone(entry.Address)
+	reval.Endpoints = slices.Clone(entry.Endpoints)
+	return &reval
+}
@@ -0,0 +1,101 @@
+package addressbook
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+)
+
+// Aufbau einer Adressbuch Datei (Version 1), deterministisches CBOR:
+//
+//	{1: Version, 2: [AddressBookEntry, ...]}
+//
+// Die Datei wird über eine temporäre Datei und Umbenennen geschrieben, damit sie nie unvollständig ist.
+
+const AddressBookVersion1 uint8 = 1
+
+type _AddressBookFile struct {
+	Version uint8               `cbor:"1"`
+	Entries []*AddressBookEntry `cbor:"2"`
+}
+
+// Lädt ein Adressbuch aus einer Datei, ist sie nicht vorhanden wird ein leeres Adressbuch erzeugt.
+// Änderungen werden automatisch in die Datei geschrieben
+func LoadAddressBookFile(path string) (*AddressBook, error) {
+	book := &AddressBook{path: path, lock: new(sync.Mutex), entries: make(map[string]*AddressBookEntry)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return book, nil
+		}
+		return nil, err
+	}
+
+	file := new(_AddressBookFile)
+	if err := cbor.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddressBookFile, err)
+	}
+	if file.Version != AddressBookVersion1 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedAddressBookVersion, file.Version)
+	}
+
+	for _, entry := range file.Entries {
+		if entry == nil {
+			return nil, ErrInvalidAddressBookFile
+		}
+		addr, err := entry.GetAddress()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidAddressBookFile, err)
+		}
+		if entry.TrustLevel < TrustUntrusted || entry.TrustLevel > TrustPinned {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidAddressBookFile, ErrInvalidTrustLevel)
+		}
+		book.entries[addr.ToString()] = entry
+	}
+
+	return book, nil
+}
+
+// Schreibt das Adressbuch in seine Datei
+func (o *AddressBook) Save() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o._SaveLocked()
+}
+
+func (o *AddressBook) _SaveLocked() error {
+	if o.path == "" {
+		return nil
+	}
+
+	file := &_AddressBookFile{Version: AddressBookVersion1, Entries: make([]*AddressBookEntry, 0, len(o.entries))}
+	for _, entry := range o.entries {
+		file.Entries = append(file.Entries, entry)
+	}
+	data, err := crypto.CanonicalEncMode().Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(o.path), filepath.Base(o.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+	return os.Rename(tempFile.Name(), o.path)
+}
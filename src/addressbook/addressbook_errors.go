@@ -0,0 +1,11 @@
+package addressbook
+
+import "errors"
+
+var (
+	ErrInvalidAddressBookFile        = errors.New("invalid address book file")
+	ErrUnsupportedAddressBookVersion = errors.New("unsupported address book version")
+	ErrInvalidTrustLevel             = errors.New("invalid trust level")
+	ErrUntrustedPeer                 = errors.New("peer address is marked as untrusted")
+	ErrPinnedIdentityMismatch        = errors.New("endpoint is pinned to a different address")
+)
@@ -8,7 +8,10 @@ const (
 	Base32DefaultBase32Alphabet Base32Alphabet    = Base32Alphabet("qpzry9x8gf2tvdw0s3jn54khce6mua7l")
 	SHA_256                     HashAlgorithm     = 1
 	SHA3_256                    HashAlgorithm     = 2
+	BLAKE3_256                  HashAlgorithm     = 3
 	Type_Ed25519                OpenKeyP2PKeyType = 0
+	Type_Secp256k1              OpenKeyP2PKeyType = 1
+	Type_BLS12381               OpenKeyP2PKeyType = 2
 
 	// Hash Methods
 	DEFAULT_HASH_METHODE_256BIT = SHA_256
@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// Fehler welche die Decoder zurückgeben dürfen, alle anderen Fehler gelten als Fund
+var fuzzAddressErrors = []error{
+	ErrInvalidAddressPrefix,
+	ErrInvalidAddressLength,
+	ErrInvalidAddressEncoding,
+	ErrInvalidAddressChecksum,
+	ErrInvalidAddressKey,
+	ErrUnknownAddressKeyType,
+	ErrUnsupportedAddressVersion,
+}
+
+// Gültige Adressen aller Schlüsseltypen als Ausgangsmaterial, die Seeds sind fest damit der Korpus reproduzierbar ist
+func _FuzzSeedAddresses(f *testing.F) []*OpenKeyP2PAddress {
+	reval := make([]*OpenKeyP2PAddress, 0)
+	for i, keyType := range []openkeyp2p.OpenKeyP2PKeyType{openkeyp2p.Type_Ed25519, openkeyp2p.Type_Secp256k1, openkeyp2p.Type_BLS12381} {
+		signer, err := NewSignerFromSeed(keyType, bytes.Repeat([]byte{byte(i + 1)}, 32))
+		if err != nil {
+			f.Fatal(err)
+		}
+		addr, err := OpenKeyP2PAddressFromSigner(signer)
+		if err != nil {
+			f.Fatal(err)
+		}
+		reval = append(reval, addr)
+	}
+	return reval
+}
+
+func _FuzzCheckError(t *testing.T, input any, err error) {
+	for _, addressErr := range fuzzAddressErrors {
+		if errors.Is(err, addressErr) {
+			return
+		}
+	}
+	t.Fatalf("untyped error for input %q: %v", input, err)
+}
+
+// Eine gültige Adresse muss über alle Darstellungen exakt wieder eingelesen werden können
+func _FuzzCheckRoundTrip(t *testing.T, addr *OpenKeyP2PAddress) {
+	fromString, err := OpenKeyP2PAddressDecodeFromString(addr.ToString())
+	if err != nil || !fromString.Equal(addr) || fromString.ToString() != addr.ToString() {
+		t.Fatalf("string round trip failed for %s: %v", addr.ToString(), err)
+	}
+
+	fromLegacy, err := OpenKeyP2PAddressDecodeFromString(addr.ToLegacyString())
+	if err != nil || !fromLegacy.Equal(addr) {
+		t.Fatalf("legacy round trip failed for %s: %v", addr.ToLegacyString(), err)
+	}
+
+	fromBytes, err := OpenKeyP2PAddressDecodeFromByteSlice(addr.ToByteSlice())
+	if err != nil || !fromBytes.Equal(addr) || !bytes.Equal(fromBytes.ToByteSlice(), addr.ToByteSlice()) {
+		t.Fatalf("byte round trip failed for %x: %v", addr.ToByteSlice(), err)
+	}
+}
+
+func FuzzOpenKeyP2PAddressDecodeFromString(f *testing.F) {
+	for _, addr := range _FuzzSeedAddresses(f) {
+		f.Add(addr.ToString())
+		f.Add(strings.ToUpper(addr.ToString()))
+		f.Add(addr.ToLegacyString())
+	}
+	f.Add("")
+	f.Add(string(openkeyp2p.Prefix))
+
+	f.Fuzz(func(t *testing.T, input string) {
+		addr, err := OpenKeyP2PAddressDecodeFromString(input)
+		if err != nil {
+			_FuzzCheckError(t, input, err)
+			return
+		}
+		_FuzzCheckRoundTrip(t, addr)
+	})
+}
+
+func FuzzOpenKeyP2PAddressDecodeFromByteSlice(f *testing.F) {
+	for _, addr := range _FuzzSeedAddresses(f) {
+		f.Add(addr.ToByteSlice())
+	}
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		addr, err := OpenKeyP2PAddressDecodeFromByteSlice(input)
+		if err != nil {
+			_FuzzCheckError(t, input, err)
+			return
+		}
+		_FuzzCheckRoundTrip(t, addr)
+	})
+}
+
+func FuzzBech32Decode(f *testing.F) {
+	hrp := string(openkeyp2p.Prefix)
+	for _, addr := range _FuzzSeedAddresses(f) {
+		f.Add(addr.ToString())
+	}
+	f.Add(hrp + "1")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		data, err := _Bech32Decode(hrp, input)
+		if err != nil {
+			_FuzzCheckError(t, input, err)
+
+			// Angegebene Fehlerpositionen müssen innerhalb der Eingabe liegen
+			var checksumErr *AddressChecksumError
+			if errors.As(err, &checksumErr) {
+				for _, pos := range checksumErr.Positions {
+					if pos < 0 || pos >= len(input) {
+						t.Fatalf("error position %d outside of input %q", pos, input)
+					}
+				}
+			}
+			return
+		}
+
+		// Eine gültige Zeichenkette wird in Kleinschreibung wieder identisch kodiert
+		for _, value := range data {
+			if value >= 32 {
+				t.Fatalf("decoded value %d out of range for input %q", value, input)
+			}
+		}
+		if encoded := _Bech32Encode(hrp, data); encoded != strings.ToLower(input) {
+			t.Fatalf("bech32 round trip failed: %q != %q", encoded, input)
+		}
+	})
+}
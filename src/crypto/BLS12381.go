@@ -0,0 +1,147 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"golang.org/x/crypto/hkdf"
+)
+
+// BLS Signaturen im "minimal-pubkey-size" Schema, öffentliche Schlüssel liegen in G1 und Signaturen in G2
+const (
+	bls12381PublicKeySize = 48
+	bls12381SignatureSize = 96
+	bls12381SignatureDST  = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_"
+	bls12381KeyGenSalt    = "BLS-SIG-KEYGEN-SALT-"
+)
+
+// Ordnung der Untergruppe r
+var bls12381GroupOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// BLS12-381 Implementierung von OpenKeyP2PSigner
+type BLS12381Signer struct {
+	secretKey *big.Int
+	publicKey []byte
+}
+
+// Erzeugt einen Signer aus einem vorhandenen BLS12-381 Secret Key (32 Byte, Big Endian)
+func NewBLS12381Signer(secretKey []byte) (*BLS12381Signer, error) {
+	sk := new(big.Int).SetBytes(secretKey)
+	if sk.Sign() == 0 || sk.Cmp(bls12381GroupOrder) >= 0 {
+		return nil, fmt.Errorf("invalid bls12-381 secret key")
+	}
+
+	g1 := bls12381.NewG1()
+	pubPoint := g1.MulScalarBig(g1.New(), g1.One(), sk)
+
+	return &BLS12381Signer{secretKey: sk, publicKey: g1.ToCompressed(pubPoint)}, nil
+}
+
+func (o *BLS12381Signer) KeyType() openkeyp2p.OpenKeyP2PKeyType {
+	return openkeyp2p.Type_BLS12381
+}
+
+func (o *BLS12381Signer) PublicKey() openkeyp2p.OpenKeyP2PPublicKey {
+	return openkeyp2p.OpenKeyP2PPublicKey(o.publicKey)
+}
+
+func (o *BLS12381Signer) SignDigest(digest []byte) ([]byte, error) {
+	g2 := bls12381.NewG2()
+	msgPoint, err := g2.HashToCurve(digest, []byte(bls12381SignatureDST))
+	if err != nil {
+		return nil, err
+	}
+	sigPoint := g2.MulScalarBig(g2.New(), msgPoint, o.secretKey)
+	return g2.ToCompressed(sigPoint), nil
+}
+
+func (o *BLS12381Signer) CurvePrivateKey() ([]byte, error) {
+	return _DeriveCurvePrivateKeyFromSecret(o.secretKey.FillBytes(make([]byte, 32)), openkeyp2p.Type_BLS12381)
+}
+
+// BLS12-381 Implementierung von OpenKeyP2PVerifier
+type bls12381Verifier struct{}
+
+func (bls12381Verifier) KeyType() openkeyp2p.OpenKeyP2PKeyType {
+	return openkeyp2p.Type_BLS12381
+}
+
+func (bls12381Verifier) PublicKeySize() int {
+	return bls12381PublicKeySize
+}
+
+func (bls12381Verifier) ValidatePublicKey(pubKey openkeyp2p.OpenKeyP2PPublicKey) error {
+	if len(pubKey) != bls12381PublicKeySize {
+		return fmt.Errorf("invalid bls12-381 public key size")
+	}
+
+	// FromCompressed prüft ob der Punkt auf der Kurve und in der richtigen Untergruppe liegt
+	g1 := bls12381.NewG1()
+	pubPoint, err := g1.FromCompressed(pubKey)
+	if err != nil {
+		return fmt.Errorf("invalid bls12-381 public key: %w", err)
+	}
+	if g1.IsZero(pubPoint) {
+		return fmt.Errorf("invalid bls12-381 public key: identity point")
+	}
+	return nil
+}
+
+func (o bls12381Verifier) VerifyDigest(pubKey openkeyp2p.OpenKeyP2PPublicKey, digest []byte, signature []byte) (bool, error) {
+	if digest == nil {
+		return false, fmt.Errorf("invalid hash digest")
+	}
+	if err := o.ValidatePublicKey(pubKey); err != nil {
+		return false, err
+	}
+	if len(signature) != bls12381SignatureSize {
+		return false, nil
+	}
+
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	pubPoint, _ := g1.FromCompressed(pubKey)
+	sigPoint, err := g2.FromCompressed(signature)
+	if err != nil {
+		return false, nil
+	}
+
+	msgPoint, err := g2.HashToCurve(digest, []byte(bls12381SignatureDST))
+	if err != nil {
+		return false, err
+	}
+
+	// e(pk, H(m)) == e(g1, sig)
+	engine := bls12381.NewEngine()
+	engine.AddPair(pubPoint, msgPoint)
+	engine.AddPairInv(g1.One(), sigPoint)
+	return engine.Check(), nil
+}
+
+// Leitet den Secret Key nach dem KeyGen Verfahren aus draft-irtf-cfrg-bls-signature ab
+func (bls12381Verifier) SignerFromSeed(seed []byte) (OpenKeyP2PSigner, error) {
+	if len(seed) < 32 {
+		return nil, fmt.Errorf("invalid seed size %d", len(seed))
+	}
+
+	salt := []byte(bls12381KeyGenSalt)
+	ikm := append(append([]byte{}, seed...), 0)
+	keyInfo := []byte{0, 48}
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		saltHash := sha256.Sum256(salt)
+		salt = saltHash[:]
+
+		okm := make([]byte, 48)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, keyInfo), okm); err != nil {
+			return nil, err
+		}
+		sk.SetBytes(okm)
+		sk.Mod(sk, bls12381GroupOrder)
+	}
+
+	return NewBLS12381Signer(sk.FillBytes(make([]byte, 32)))
+}
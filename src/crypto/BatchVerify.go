@@ -0,0 +1,172 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"slices"
+
+	"filippo.io/edwards25519"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// Stapelprüfung von ED25519 Signaturen. Für n Signaturen (R_i, s_i) über M_i mit Schlüssel A_i und zufälligen
+// 128 Bit Gewichten z_i wird eine einzige Multiskalar Multiplikation ausgeführt:
+//
+//	[8]([-Σ z_i·s_i]B + Σ [z_i]R_i + Σ [z_i·k_i]A_i) == 0,  k_i = SHA-512(R_i || A_i || M_i) mod L
+//
+// Schlägt die Stapelprüfung fehl, werden alle Einträge einzeln geprüft um die ungültigen Einträge zu bestimmen.
+// Stapel- und Einzelprüfung verwenden dieselbe Regel mit Kofaktor (ZIP-215), eine Signatur erhält daher
+// unabhängig von der Stapelgröße immer dasselbe Ergebnis.
+
+// Unterhalb dieser Anzahl ist die Einzelprüfung schneller
+const minSignatureBatchSize = 4
+
+type _SignatureBatchEntry struct {
+	keyType   openkeyp2p.OpenKeyP2PKeyType
+	pubKey    openkeyp2p.OpenKeyP2PPublicKey
+	digest    []byte
+	signature []byte
+	address   *OpenKeyP2PAddress
+}
+
+// Sammelt Signaturen für eine gemeinsame Prüfung
+type SignatureBatch struct {
+	entries []_SignatureBatchEntry
+	store   *RevocationStore
+}
+
+// Erzeugt einen Stapel welcher Widerrufe gegen den Standard Store prüft
+func NewSignatureBatch(capacity int) *SignatureBatch {
+	return NewSignatureBatchWithStore(defaultRevocationStore, capacity)
+}
+
+// Erzeugt einen Stapel welcher Widerrufe gegen store prüft
+func NewSignatureBatchWithStore(store *RevocationStore, capacity int) *SignatureBatch {
+	return &SignatureBatch{entries: make([]_SignatureBatchEntry, 0, capacity), store: store}
+}
+
+// Fügt eine ED25519 Signatur über einen Hash hinzu, entspricht OpenKeyP2PSignature.VerifySignatureHashDigest
+func (o *SignatureBatch) Add(pubKey openkeyp2p.OpenKeyP2PPublicKey, dataHash openkeyp2p.HashSlice, signature OpenKeyP2PSignature) {
+	o.entries = append(o.entries, _SignatureBatchEntry{keyType: openkeyp2p.Type_Ed25519, pubKey: pubKey, digest: dataHash, signature: signature})
+}
+
+// Fügt eine Signatur einer Adresse hinzu, entspricht OpenKeyP2PAddress.VerifySignatureWithStore inklusive Widerrufsprüfung.
+// Der Widerruf wird erst von Verify geprüft. Signaturen anderer Schlüsseltypen als ED25519 werden bei der Prüfung einzeln geprüft
+func (o *SignatureBatch) AddAddressSignature(address *OpenKeyP2PAddress, signature OpenKeyP2PSignature, dataHash openkeyp2p.HashSlice) error {
+	addrHash, err := address.ComputeHash()
+	if err != nil {
+		return err
+	}
+
+	dataAddressHashCombination, err := ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, addrHash, dataHash)
+	if err != nil {
+		return err
+	}
+
+	o.entries = append(o.entries, _SignatureBatchEntry{keyType: address.KeyType, pubKey: address.PubKey, digest: dataAddressHashCombination, signature: signature, address: address})
+	return nil
+}
+
+// Gibt die Anzahl der Einträge zurück
+func (o *SignatureBatch) Len() int {
+	return len(o.entries)
+}
+
+// Prüft alle Einträge, sind nicht alle gültig werden die Indizes der ungültigen Einträge zurückgegeben
+func (o *SignatureBatch) Verify() (bool, []int) {
+	ed25519Entries := make([]int, 0, len(o.entries))
+	invalid := make([]int, 0)
+	for i, entry := range o.entries {
+		// Signaturen widerrufener Adressen sind immer ungültig
+		if entry.address != nil && o.store.IsAddressRevoked(entry.address) {
+			invalid = append(invalid, i)
+		} else if entry.keyType == openkeyp2p.Type_Ed25519 {
+			ed25519Entries = append(ed25519Entries, i)
+		} else if !_VerifySignatureBatchEntry(&entry) {
+			invalid = append(invalid, i)
+		}
+	}
+
+	// Schlägt die Stapelprüfung fehl, werden die ED25519 Einträge einzeln geprüft
+	if len(ed25519Entries) < minSignatureBatchSize || !o._VerifyEd25519Batch(ed25519Entries) {
+		for _, i := range ed25519Entries {
+			if !_VerifySignatureBatchEntry(&o.entries[i]) {
+				invalid = append(invalid, i)
+			}
+		}
+	}
+
+	if len(invalid) == 0 {
+		return true, nil
+	}
+	slices.Sort(invalid)
+	return false, invalid
+}
+
+func (o *SignatureBatch) _VerifyEd25519Batch(indices []int) bool {
+	scalars := make([]*edwards25519.Scalar, 0, 1+2*len(indices))
+	points := make([]*edwards25519.Point, 0, 1+2*len(indices))
+	sumZS := edwards25519.NewScalar()
+
+	// Platz für [-Σ z_i·s_i]B
+	scalars = append(scalars, nil)
+	points = append(points, edwards25519.NewGeneratorPoint())
+
+	weightBytes := make([]byte, 32)
+	for _, i := range indices {
+		entry := &o.entries[i]
+		if len(entry.pubKey) != ed25519.PublicKeySize || len(entry.signature) != ed25519.SignatureSize {
+			return false
+		}
+
+		pubKeyPoint, err := new(edwards25519.Point).SetBytes(entry.pubKey)
+		if err != nil {
+			return false
+		}
+		rPoint, err := new(edwards25519.Point).SetBytes(entry.signature[:32])
+		if err != nil {
+			return false
+		}
+		s, err := edwards25519.NewScalar().SetCanonicalBytes(entry.signature[32:])
+		if err != nil {
+			return false
+		}
+
+		hasher := sha512.New()
+		hasher.Write(entry.signature[:32])
+		hasher.Write(entry.pubKey)
+		hasher.Write(entry.digest)
+		k, err := edwards25519.NewScalar().SetUniformBytes(hasher.Sum(nil))
+		if err != nil {
+			return false
+		}
+
+		// Zufälliges 128 Bit Gewicht, die oberen 16 Bytes bleiben 0
+		clear(weightBytes)
+		if _, err := rand.Read(weightBytes[:16]); err != nil {
+			return false
+		}
+		z, err := edwards25519.NewScalar().SetCanonicalBytes(weightBytes)
+		if err != nil {
+			return false
+		}
+
+		sumZS.MultiplyAdd(z, s, sumZS)
+		scalars = append(scalars, z, edwards25519.NewScalar().Multiply(z, k))
+		points = append(points, rPoint, pubKeyPoint)
+	}
+	scalars[0] = edwards25519.NewScalar().Negate(sumZS)
+
+	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+	return check.MultByCofactor(check).Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+func _VerifySignatureBatchEntry(entry *_SignatureBatchEntry) bool {
+	verifier, err := GetKeyTypeVerifier(entry.keyType)
+	if err != nil || len(entry.pubKey) != verifier.PublicKeySize() {
+		return false
+	}
+	isValid, err := verifier.VerifyDigest(entry.pubKey, entry.digest, entry.signature)
+	return err == nil && isValid
+}
@@ -0,0 +1,208 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"filippo.io/edwards25519"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+type _SignedTestItem struct {
+	signer    Ed25519Signer
+	dataHash  openkeyp2p.HashSlice
+	signature OpenKeyP2PSignature
+}
+
+func _GenerateSignedTestItems(tb testing.TB, count int) []_SignedTestItem {
+	reval := make([]_SignedTestItem, 0, count)
+	for i := 0; i < count; i++ {
+		_, privKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		signer := Ed25519Signer(privKey)
+
+		dataHash, err := ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, []byte(fmt.Sprintf("record %d", i)))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		signature, err := signer.SignDigest(dataHash)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		reval = append(reval, _SignedTestItem{signer: signer, dataHash: dataHash, signature: signature})
+	}
+	return reval
+}
+
+func _NewTestBatch(items []_SignedTestItem) *SignatureBatch {
+	batch := NewSignatureBatch(len(items))
+	for _, item := range items {
+		batch.Add(item.signer.PublicKey(), item.dataHash, item.signature)
+	}
+	return batch
+}
+
+// Erzeugt eine Signatur deren R um einen Punkt der Ordnung 2 verschoben ist, sie ist nur mit Kofaktor gültig
+func _SignWithSmallOrderR(tb testing.TB, signer Ed25519Signer, message []byte) OpenKeyP2PSignature {
+	secret, _, err := _Ed25519SignerToScalar(signer)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	nonceBytes := make([]byte, 64)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		tb.Fatal(err)
+	}
+	nonce, _ := edwards25519.NewScalar().SetUniformBytes(nonceBytes)
+
+	// (0, -1) hat die Ordnung 2
+	torsionBytes, _ := hex.DecodeString("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	torsion, err := new(edwards25519.Point).SetBytes(torsionBytes)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	rPoint := new(edwards25519.Point).Add(new(edwards25519.Point).ScalarBaseMult(nonce), torsion)
+
+	hasher := sha512.New()
+	hasher.Write(rPoint.Bytes())
+	hasher.Write(signer.PublicKey())
+	hasher.Write(message)
+	k, _ := edwards25519.NewScalar().SetUniformBytes(hasher.Sum(nil))
+	s := edwards25519.NewScalar().MultiplyAdd(k, secret, nonce)
+
+	return append(rPoint.Bytes(), s.Bytes()...)
+}
+
+func TestSignatureBatchLocatesInvalidSignature(t *testing.T) {
+	items := _GenerateSignedTestItems(t, 64)
+	if valid, invalid := _NewTestBatch(items).Verify(); !valid {
+		t.Fatalf("valid batch rejected, invalid entries %v", invalid)
+	}
+
+	items[7].signature = append(OpenKeyP2PSignature{}, items[7].signature...)
+	items[7].signature[40] ^= 0x01
+	valid, invalid := _NewTestBatch(items).Verify()
+	if valid || len(invalid) != 1 || invalid[0] != 7 {
+		t.Fatalf("manipulated signature not located, got %v", invalid)
+	}
+}
+
+// Eine Signatur muss unabhängig von der Stapelgröße und von der Einzelprüfung dasselbe Ergebnis erhalten
+func TestSignatureBatchConsistentVerdict(t *testing.T) {
+	items := _GenerateSignedTestItems(t, 2*minSignatureBatchSize)
+	items[0].signature = _SignWithSmallOrderR(t, items[0].signer, items[0].dataHash)
+
+	if ed25519.Verify(ed25519.PublicKey(items[0].signer.PublicKey()), items[0].dataHash, items[0].signature) {
+		t.Fatal("cofactorless verification accepted the small order signature")
+	}
+
+	single, err := items[0].signature.VerifySignatureHashDigest(items[0].dataHash, items[0].signer.PublicKey())
+	if err != nil || !single {
+		t.Fatalf("single verification rejected the small order signature: %v", err)
+	}
+	for _, size := range []int{1, minSignatureBatchSize - 1, len(items)} {
+		if batch, _ := _NewTestBatch(items[:size]).Verify(); batch != single {
+			t.Fatalf("batch of %d returned %t, single verification %t", size, batch, single)
+		}
+	}
+}
+
+// Schlüssel mit Anteil aus der Torsionsgruppe dürfen weder geprüft noch als Adresse eingelesen werden,
+// sonst hätte ein privater Schlüssel unter ZIP-215 mehrere gültige Adressen
+func TestEd25519RejectsTorsionKeys(t *testing.T) {
+	items := _GenerateSignedTestItems(t, 1)
+	publicKey := items[0].signer.PublicKey()
+	if err := (ed25519Verifier{}).ValidatePublicKey(publicKey); err != nil {
+		t.Fatalf("valid key rejected: %v", err)
+	}
+
+	torsionBytes, _ := hex.DecodeString("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	torsion, err := new(edwards25519.Point).SetBytes(torsionBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, err := new(edwards25519.Point).SetBytes(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mixedOrder := new(edwards25519.Point).Add(point, torsion).Bytes()
+
+	for name, key := range map[string][]byte{"small order": torsionBytes, "mixed order": mixedOrder} {
+		if err := (ed25519Verifier{}).ValidatePublicKey(key); err == nil {
+			t.Fatalf("%s key accepted by validation", name)
+		}
+		if _, err := OpenKeyP2PAddressFromKey(openkeyp2p.Type_Ed25519, key); err == nil {
+			t.Fatalf("%s key accepted as address", name)
+		}
+		addr := &OpenKeyP2PAddress{Prefix: openkeyp2p.Prefix, KeyType: openkeyp2p.Type_Ed25519, PubKey: key}
+		if _, err := OpenKeyP2PAddressDecodeFromString(addr.ToString()); err == nil {
+			t.Fatalf("%s key accepted by string decoding", name)
+		}
+		if _, err := OpenKeyP2PAddressDecodeFromByteSlice(addr.ToByteSlice()); err == nil {
+			t.Fatalf("%s key accepted by byte decoding", name)
+		}
+	}
+}
+
+// Ein Widerruf der nach dem Hinzufügen, aber vor der Prüfung eintrifft, muss die Signatur ungültig machen
+func TestSignatureBatchRevokedBeforeVerify(t *testing.T) {
+	items := _GenerateSignedTestItems(t, 2)
+	store := NewRevocationStore()
+	batch := NewSignatureBatchWithStore(store, len(items))
+	for _, item := range items {
+		addr, err := OpenKeyP2PAddressFromSigner(item.signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature, err := AddressSign(item.signer, item.dataHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := batch.AddAddressSignature(addr, signature, item.dataHash); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	statement, err := NewSelfRevocation(items[1].signer, RevocationReasonKeyCompromise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddRevocation(statement); err != nil {
+		t.Fatal(err)
+	}
+	if valid, invalid := batch.Verify(); valid || len(invalid) != 1 || invalid[0] != 1 {
+		t.Fatalf("revoked signature not rejected, got %v", invalid)
+	}
+}
+
+func BenchmarkVerifySignature(b *testing.B) {
+	items := _GenerateSignedTestItems(b, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := items[0].signature.VerifySignatureHashDigest(items[0].dataHash, items[0].signer.PublicKey()); err != nil || !ok {
+			b.Fatal("invalid signature")
+		}
+	}
+}
+
+func BenchmarkSignatureBatch(b *testing.B) {
+	for _, size := range []int{4, 16, 64, 256, 1024} {
+		items := _GenerateSignedTestItems(b, size)
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if valid, _ := _NewTestBatch(items).Verify(); !valid {
+					b.Fatal("invalid batch")
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*size), "ns/sig")
+		})
+	}
+}
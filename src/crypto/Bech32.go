@@ -0,0 +1,210 @@
+package crypto
+
+import (
+	"fmt"
+	"strings"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// BCH Prüfsumme nach BIP-173/BIP-350 (bech32m), die Prüfsumme wird über den Prefix sowie alle
+// Base32 Symbole gebildet und kann daher einzelne Tippfehler lokalisieren
+//
+// BIP-173 begrenzt Adressen auf 90 Zeichen, BLS12-381 Adressen sind mit 91 Zeichen länger. Die Länge
+// wird daher auf bech32MaxLength erweitert, Fehler werden weiterhin erkannt. Die Garantie dass eine
+// einzelne falsche Stelle eindeutig lokalisiert werden kann gilt jedoch nur bis bech32StandardLength,
+// bei längeren Adressen wird deshalb keine Position angegeben
+
+const (
+	bech32Separator      = '1'
+	bech32ChecksumLength = 6
+	bech32StandardLength = 90
+	bech32MaxLength      = 128
+	bech32mConstant      = 0x2bc830a3
+)
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// Gibt die Position des Zeichens im Base32 Alphabet zurück, -1 wenn es nicht enthalten ist
+var bech32CharsetRev = func() [128]int8 {
+	var reval [128]int8
+	for i := range reval {
+		reval[i] = -1
+	}
+	for i, char := range openkeyp2p.Base32DefaultBase32Alphabet {
+		reval[char] = int8(i)
+	}
+	return reval
+}()
+
+func _Bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, value := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(value)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func _Bech32HrpExpand(hrp string) []byte {
+	reval := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		reval = append(reval, hrp[i]>>5)
+	}
+	reval = append(reval, 0)
+	for i := 0; i < len(hrp); i++ {
+		reval = append(reval, hrp[i]&31)
+	}
+	return reval
+}
+
+func _Bech32VerifyChecksum(hrp string, data []byte) bool {
+	values := append(_Bech32HrpExpand(hrp), data...)
+	return _Bech32Polymod(values) == bech32mConstant
+}
+
+func _Bech32CreateChecksum(hrp string, data []byte) []byte {
+	values := append(_Bech32HrpExpand(hrp), data...)
+	values = append(values, make([]byte, bech32ChecksumLength)...)
+	polymod := _Bech32Polymod(values) ^ bech32mConstant
+	checksum := make([]byte, bech32ChecksumLength)
+	for i := range checksum {
+		checksum[i] = byte(polymod>>(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// Sucht nach einer einzelnen falschen Stelle welche die Prüfsumme wieder gültig macht, bis
+// bech32StandardLength garantiert die BCH Prüfsumme dass es dafür höchstens einen Kandidaten gibt
+func _Bech32LocateSingleError(hrp string, data []byte) []int {
+	candidate := make([]byte, len(data))
+	copy(candidate, data)
+	for pos := range candidate {
+		original := candidate[pos]
+		for value := byte(0); value < 32; value++ {
+			if value == original {
+				continue
+			}
+			candidate[pos] = value
+			if _Bech32VerifyChecksum(hrp, candidate) {
+				return []int{pos}
+			}
+		}
+		candidate[pos] = original
+	}
+	return nil
+}
+
+// Wandelt Gruppen von fromBits Bits in Gruppen von toBits Bits um
+func _Bech32ConvertBits(data []byte, fromBits uint, toBits uint, pad bool) ([]byte, error) {
+	acc := uint32(0)
+	bits := uint(0)
+	maxv := uint32(1)<<toBits - 1
+	reval := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, value := range data {
+		if uint32(value)>>fromBits != 0 {
+			return nil, fmt.Errorf("invalid data range")
+		}
+		acc = acc<<fromBits | uint32(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			reval = append(reval, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			reval = append(reval, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, fmt.Errorf("invalid padding")
+	}
+	return reval, nil
+}
+
+// Kodiert die 5 Bit Werte mit Prefix, Separator und Prüfsumme
+func _Bech32Encode(hrp string, data []byte) string {
+	combined := make([]byte, 0, len(data)+bech32ChecksumLength)
+	combined = append(combined, data...)
+	combined = append(combined, _Bech32CreateChecksum(hrp, data)...)
+	var builder strings.Builder
+	builder.Grow(len(hrp) + 1 + len(combined))
+	builder.WriteString(hrp)
+	builder.WriteByte(bech32Separator)
+	for _, value := range combined {
+		builder.WriteByte(openkeyp2p.Base32DefaultBase32Alphabet[value])
+	}
+	return builder.String()
+}
+
+// Dekodiert eine Bech32 Zeichenkette und gibt die 5 Bit Werte ohne Prüfsumme zurück,
+// Positionsangaben in Fehlern beziehen sich auf die gesamte Zeichenkette
+func _Bech32Decode(hrp string, bech string) ([]byte, error) {
+	// Überlange Eingaben werden vor der Fehlersuche abgelehnt
+	if len(bech) > bech32MaxLength {
+		return nil, fmt.Errorf("%w: address too long", ErrInvalidAddressLength)
+	}
+
+	// Groß- und Kleinschreibung darf nicht gemischt werden
+	lowered := strings.ToLower(bech)
+	if lowered != bech && strings.ToUpper(bech) != bech {
+		return nil, fmt.Errorf("%w: mixed case address", ErrInvalidAddressEncoding)
+	}
+
+	dataStart := len(hrp) + 1
+	if len(lowered) < dataStart+bech32ChecksumLength || lowered[:len(hrp)] != hrp || lowered[len(hrp)] != bech32Separator {
+		return nil, fmt.Errorf("%w: invalid bech32 address", ErrInvalidAddressLength)
+	}
+
+	data := make([]byte, 0, len(lowered)-dataStart)
+	for i := dataStart; i < len(lowered); i++ {
+		char := lowered[i]
+		if char >= 128 || bech32CharsetRev[char] == -1 {
+			return nil, &AddressChecksumError{Positions: []int{i}, InvalidCharacter: true}
+		}
+		data = append(data, byte(bech32CharsetRev[char]))
+	}
+
+	if !_Bech32VerifyChecksum(hrp, data) {
+		// Über der Standardlänge wäre ein gefundener Kandidat nicht eindeutig
+		if len(bech) > bech32StandardLength {
+			return nil, &AddressChecksumError{}
+		}
+		positions := _Bech32LocateSingleError(hrp, data)
+		for i := range positions {
+			positions[i] += dataStart
+		}
+		return nil, &AddressChecksumError{Positions: positions}
+	}
+
+	return data[:len(data)-bech32ChecksumLength], nil
+}
+
+// Wird zurückgegeben wenn die Prüfsumme einer Adresse ungültig ist, sofern die Fehlerstelle
+// bestimmt werden konnte, enthält Positions die Stelle in der Zeichenkette
+type AddressChecksumError struct {
+	Positions        []int
+	InvalidCharacter bool
+}
+
+func (o *AddressChecksumError) Unwrap() error {
+	if o.InvalidCharacter {
+		return ErrInvalidAddressEncoding
+	}
+	return ErrInvalidAddressChecksum
+}
+
+func (o *AddressChecksumError) Error() string {
+	if o.InvalidCharacter {
+		return fmt.Sprintf("invalid address character at position %d", o.Positions[0])
+	}
+	if len(o.Positions) == 0 {
+		return "address checksum invalid"
+	}
+	return fmt.Sprintf("address checksum invalid, probable error at position %v", o.Positions)
+}
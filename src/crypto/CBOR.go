@@ -0,0 +1,11 @@
+package crypto
+
+import "github.com/fxamacker/cbor/v2"
+
+// Deterministischer CBOR Encoder (RFC 8949 Core Deterministic Encoding), wird für Signaturen verwendet
+var canonicalEncMode, _ = cbor.CoreDetEncOptions().EncMode()
+
+// Gibt den deterministischen CBOR Encoder zurück, gleiche Werte werden immer identisch kodiert
+func CanonicalEncMode() cbor.EncMode {
+	return canonicalEncMode
+}
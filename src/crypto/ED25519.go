@@ -1,9 +1,13 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/ed25519"
+	"crypto/sha512"
+	"fmt"
 
 	"filippo.io/edwards25519"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
 )
 
 func Ed25519ToCurve25519PublicKey(edPub ed25519.PublicKey) ([]byte, error) {
@@ -18,6 +22,23 @@ func Ed25519ToCurve25519PublicKey(edPub ed25519.PublicKey) ([]byte, error) {
 	return curvePub[:], nil
 }
 
+func Ed25519ToCurve25519PrivateKey(edPriv ed25519.PrivateKey) ([]byte, error) {
+	if len(edPriv) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	// Der Skalar wird wie bei ED25519 aus dem SHA-512 Hash des Seeds gebildet (RFC 8032)
+	digest := sha512.Sum512(edPriv.Seed())
+
+	// Clamping nach RFC 7748
+	curvePriv := make([]byte, 32)
+	copy(curvePriv, digest[:32])
+	curvePriv[0] &= 248
+	curvePriv[31] &= 127
+	curvePriv[31] |= 64
+	return curvePriv, nil
+}
+
 func GenerateKeyPairFromSeed(seed []byte) (ed25519.PrivateKey, ed25519.PublicKey) {
 	// Privaten Schlüssel aus dem Seed generieren
 	privKey := ed25519.NewKeyFromSeed(seed)
@@ -42,3 +63,83 @@ func PrivateKeyToPublicKey(privKey ed25519.PrivateKey) ed25519.PublicKey {
 	pubKey := privKey.Public().(ed25519.PublicKey)
 	return pubKey
 }
+
+// ED25519 Implementierung von OpenKeyP2PSigner
+type Ed25519Signer ed25519.PrivateKey
+
+func (o Ed25519Signer) KeyType() openkeyp2p.OpenKeyP2PKeyType {
+	return openkeyp2p.Type_Ed25519
+}
+
+func (o Ed25519Signer) PublicKey() openkeyp2p.OpenKeyP2PPublicKey {
+	return openkeyp2p.OpenKeyP2PPublicKey(PrivateKeyToPublicKey(ed25519.PrivateKey(o)))
+}
+
+func (o Ed25519Signer) SignDigest(digest []byte) ([]byte, error) {
+	if len(o) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	return ed25519.Sign(ed25519.PrivateKey(o), digest), nil
+}
+
+func (o Ed25519Signer) CurvePrivateKey() ([]byte, error) {
+	return Ed25519ToCurve25519PrivateKey(ed25519.PrivateKey(o))
+}
+
+// ED25519 Implementierung von OpenKeyP2PVerifier
+type ed25519Verifier struct{}
+
+// L-1 als Skalar (little endian), wird für die Prüfung der Untergruppe benötigt
+var ed25519OrderMinusOne, _ = edwards25519.NewScalar().SetCanonicalBytes([]byte{
+	0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+})
+
+func (ed25519Verifier) KeyType() openkeyp2p.OpenKeyP2PKeyType {
+	return openkeyp2p.Type_Ed25519
+}
+
+func (ed25519Verifier) PublicKeySize() int {
+	return ed25519.PublicKeySize
+}
+
+// Die Signaturprüfung nach ZIP-215 ignoriert Anteile kleiner Ordnung, ein Schlüssel A+T wäre mit demselben
+// privaten Schlüssel nutzbar wie A. Damit jeder private Schlüssel genau eine Adresse besitzt, werden nur
+// kanonisch kodierte Schlüssel aus der Untergruppe mit Primordnung L akzeptiert
+func (ed25519Verifier) ValidatePublicKey(pubKey openkeyp2p.OpenKeyP2PPublicKey) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ed25519 public key size")
+	}
+	pubKeyPoint, err := new(edwards25519.Point).SetBytes(pubKey)
+	if err != nil {
+		return fmt.Errorf("invalid ed25519 public key: %w", err)
+	}
+	if !bytes.Equal(pubKeyPoint.Bytes(), pubKey) {
+		return fmt.Errorf("invalid ed25519 public key: non canonical encoding")
+	}
+
+	// [8]A == 0 gilt für alle Punkte kleiner Ordnung
+	identity := edwards25519.NewIdentityPoint()
+	if new(edwards25519.Point).MultByCofactor(pubKeyPoint).Equal(identity) == 1 {
+		return fmt.Errorf("invalid ed25519 public key: small order point")
+	}
+
+	// [L]A == 0 gilt nur in der Untergruppe mit Primordnung, L selbst ist kein Skalar daher [L-1]A + A
+	check := new(edwards25519.Point).ScalarMult(ed25519OrderMinusOne, pubKeyPoint)
+	if check.Add(check, pubKeyPoint).Equal(identity) != 1 {
+		return fmt.Errorf("invalid ed25519 public key: not in the prime order subgroup")
+	}
+	return nil
+}
+
+func (ed25519Verifier) VerifyDigest(pubKey openkeyp2p.OpenKeyP2PPublicKey, digest []byte, signature []byte) (bool, error) {
+	return OpenKeyP2PSignature(signature).VerifySignatureHashDigest(digest, pubKey)
+}
+
+func (ed25519Verifier) SignerFromSeed(seed []byte) (OpenKeyP2PSigner, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid seed size %d", len(seed))
+	}
+	privKey, _ := GenerateKeyPairFromSeed(seed)
+	return Ed25519Signer(privKey), nil
+}
@@ -9,15 +9,40 @@ import (
 	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
 )
 
+// Aufbau einer Adresse als Zeichenkette (Version 0):
+//
+//	prefix   "okp2p"
+//	sep      "1"
+//	version  1 Symbol   AddressFormatVersion0
+//	keytype  1 Symbol   openkeyp2p.OpenKeyP2PKeyType
+//	key      n Symbole  öffentlicher Schlüssel in 5 Bit Gruppen
+//	checksum 6 Symbole  BCH Prüfsumme (bech32m) über Prefix und alle Symbole
+//
+// Alte Adressen ohne Separator ("okp2p" + Base32(keytype|key|crc32)) können während der Übergangszeit weiterhin gelesen werden.
+
+const (
+	AddressFormatVersion0 uint8 = 0
+)
+
 type OpenKeyP2PAddress struct {
-	Prefix openkeyp2p.OpenKeyP2PPrefix
-	PubKey openkeyp2p.OpenKeyP2PPublicKey
+	Prefix  openkeyp2p.OpenKeyP2PPrefix
+	KeyType openkeyp2p.OpenKeyP2PKeyType
+	PubKey  openkeyp2p.OpenKeyP2PPublicKey
 }
 
 func (o *OpenKeyP2PAddress) ToString() string {
+	keyGroups, _ := _Bech32ConvertBits(o.PubKey, 8, 5, true)
+	data := make([]byte, 0, 2+len(keyGroups))
+	data = append(data, AddressFormatVersion0, byte(o.KeyType))
+	data = append(data, keyGroups...)
+	return _Bech32Encode(string(o.Prefix), data)
+}
+
+// Gibt die Adresse im alten Format mit CRC32 Prüfsumme zurück
+func (o *OpenKeyP2PAddress) ToLegacyString() string {
 	checksum := o.ComputeChecksum()
 	bytesSlice := make([]byte, 0)
-	bytesSlice = append(bytesSlice, 0)
+	bytesSlice = append(bytesSlice, byte(o.KeyType))
 	bytesSlice = append(bytesSlice, o.PubKey...)
 	bytesSlice = append(bytesSlice, checksum...)
 	encodedPublicKey := openkeyp2p.Base32Encoding.EncodeToString(bytesSlice)
@@ -28,7 +53,7 @@ func (o *OpenKeyP2PAddress) ToByteSlice() []byte {
 	checksum := o.ComputeChecksum()
 	bytesSlice := make([]byte, 0)
 	bytesSlice = append(bytesSlice, []byte(openkeyp2p.Prefix)...)
-	bytesSlice = append(bytesSlice, 0)
+	bytesSlice = append(bytesSlice, byte(o.KeyType))
 	bytesSlice = append(bytesSlice, o.PubKey...)
 	bytesSlice = append(bytesSlice, checksum...)
 	return bytesSlice
@@ -37,16 +62,51 @@ func (o *OpenKeyP2PAddress) ToByteSlice() []byte {
 func (o *OpenKeyP2PAddress) ComputeChecksum() []byte {
 	bytesSlice := make([]byte, 0)
 	bytesSlice = append(bytesSlice, []byte(openkeyp2p.Prefix)...)
-	bytesSlice = append(bytesSlice, 0)
+	bytesSlice = append(bytesSlice, byte(o.KeyType))
 	bytesSlice = append(bytesSlice, o.PubKey...)
 	return ComputeChecksumCRC32(bytesSlice)
 }
 
+// Prüft ob beide Adressen denselben Schlüssel darstellen
+func (o *OpenKeyP2PAddress) Equal(other *OpenKeyP2PAddress) bool {
+	if o == nil || other == nil {
+		return o == other
+	}
+	return o.Prefix == other.Prefix && o.KeyType == other.KeyType && bytes.Equal(o.PubKey, other.PubKey)
+}
+
+// Gibt den öffentlichen Curve25519 Schlüssel der Adresse zurück, dieser wird für den X25519 Schlüsselaustausch verwendet,
+// nur ED25519 Adressen besitzen eine Abbildung auf Curve25519
+func (o *OpenKeyP2PAddress) CurvePublicKey() ([]byte, error) {
+	if o.KeyType != openkeyp2p.Type_Ed25519 {
+		return nil, fmt.Errorf("key type %s has no curve25519 public key", KeyTypeName(o.KeyType))
+	}
+	return Ed25519ToCurve25519PublicKey(ed25519.PublicKey(o.PubKey))
+}
+
 func (o *OpenKeyP2PAddress) ComputeHash() (openkeyp2p.HashSlice, error) {
 	return ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, o.ToByteSlice())
 }
 
+// Prüft eine per AddressSign erzeugte Signatur, für im Standard Store widerrufene Adressen wird ErrAddressRevoked zurückgegeben
 func (o *OpenKeyP2PAddress) VerifySignature(signature OpenKeyP2PSignature, dataHash openkeyp2p.HashSlice) (bool, error) {
+	return o.VerifySignatureWithStore(defaultRevocationStore, signature, dataHash)
+}
+
+// Prüft eine per AddressSign erzeugte Signatur, für in store widerrufene Adressen wird ErrAddressRevoked zurückgegeben
+func (o *OpenKeyP2PAddress) VerifySignatureWithStore(store *RevocationStore, signature OpenKeyP2PSignature, dataHash openkeyp2p.HashSlice) (bool, error) {
+	if store.IsAddressRevoked(o) {
+		return false, fmt.Errorf("%w: %s", ErrAddressRevoked, o.ToString())
+	}
+	return o._VerifySignature(signature, dataHash)
+}
+
+func (o *OpenKeyP2PAddress) _VerifySignature(signature OpenKeyP2PSignature, dataHash openkeyp2p.HashSlice) (bool, error) {
+	verifier, err := GetKeyTypeVerifier(o.KeyType)
+	if err != nil {
+		return false, err
+	}
+
 	addrHash, err := o.ComputeHash()
 	if err != nil {
 		return false, err
@@ -57,7 +117,7 @@ func (o *OpenKeyP2PAddress) VerifySignature(signature OpenKeyP2PSignature, dataH
 		return false, err
 	}
 
-	verifySigResult, err := signature.VerifySignatureHashDigest(dataAddressHashCombination, o.PubKey)
+	verifySigResult, err := verifier.VerifyDigest(o.PubKey, dataAddressHashCombination, signature)
 	if err != nil {
 		return false, err
 	}
@@ -65,52 +125,146 @@ func (o *OpenKeyP2PAddress) VerifySignature(signature OpenKeyP2PSignature, dataH
 	return verifySigResult, nil
 }
 
-func OpenKeyP2PAddressDecodeFromByteSlice(adrString []byte) (*OpenKeyP2PAddress, error) {
-	if !bytes.HasPrefix(adrString, []byte(openkeyp2p.Prefix)) {
-		return nil, fmt.Errorf("string has no valid prefix")
+// Zerlegt den Teil einer Adresse nach dem Prefix in Schlüsseltyp, Schlüssel und Prüfsumme
+func _OpenKeyP2PAddressFromPlainBytes(plainAddrByteSlice []byte) (*OpenKeyP2PAddress, error) {
+	if len(plainAddrByteSlice) < 1 {
+		return nil, fmt.Errorf("%w: address too short", ErrInvalidAddressLength)
 	}
 
-	plainAddrByteSlice := bytes.ReplaceAll(adrString, []byte(openkeyp2p.Prefix), []byte{})
+	keyType := openkeyp2p.OpenKeyP2PKeyType(plainAddrByteSlice[0])
+	verifier, err := _GetAddressKeyTypeVerifier(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	keyEnd := 1 + verifier.PublicKeySize()
+	if len(plainAddrByteSlice) != keyEnd+4 {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidAddressLength, keyEnd+4, len(plainAddrByteSlice))
+	}
 
-	newAddr := &OpenKeyP2PAddress{Prefix: openkeyp2p.Prefix, PubKey: plainAddrByteSlice[1:33]}
-	if !bytes.Equal(newAddr.ComputeChecksum(), plainAddrByteSlice[33:]) {
-		return nil, fmt.Errorf("checksum invalid")
+	newAddr := &OpenKeyP2PAddress{Prefix: openkeyp2p.Prefix, KeyType: keyType, PubKey: bytes.Clone(plainAddrByteSlice[1:keyEnd])}
+	if !bytes.Equal(newAddr.ComputeChecksum(), plainAddrByteSlice[keyEnd:]) {
+		return nil, &AddressChecksumError{}
 	}
 
-	return newAddr, nil
+	return _ValidateDecodedAddress(verifier, newAddr)
+}
+
+func OpenKeyP2PAddressDecodeFromByteSlice(adrBytes []byte) (*OpenKeyP2PAddress, error) {
+	plainAddrByteSlice, hasPrefix := bytes.CutPrefix(adrBytes, []byte(openkeyp2p.Prefix))
+	if !hasPrefix {
+		return nil, ErrInvalidAddressPrefix
+	}
+
+	return _OpenKeyP2PAddressFromPlainBytes(plainAddrByteSlice)
 }
 
 func OpenKeyP2PAddressDecodeFromString(adrString string) (*OpenKeyP2PAddress, error) {
-	if !strings.HasPrefix(adrString, string(openkeyp2p.Prefix)) {
-		return nil, fmt.Errorf("string has no valid prefix")
+	if len(adrString) < len(openkeyp2p.Prefix) || !strings.EqualFold(adrString[:len(openkeyp2p.Prefix)], string(openkeyp2p.Prefix)) {
+		return nil, ErrInvalidAddressPrefix
+	}
+
+	// Adressen mit Separator verwenden das versionierte Format, das alte Alphabet enthält keine "1"
+	if len(adrString) > len(openkeyp2p.Prefix) && adrString[len(openkeyp2p.Prefix)] == bech32Separator {
+		return _OpenKeyP2PAddressDecodeFromBech32(adrString)
 	}
 
-	base32Str := strings.ReplaceAll(adrString, string(openkeyp2p.Prefix), "")
+	// Alte Adressen werden nur in Kleinbuchstaben akzeptiert
+	base32Str, hasPrefix := strings.CutPrefix(adrString, string(openkeyp2p.Prefix))
+	if !hasPrefix {
+		return nil, ErrInvalidAddressPrefix
+	}
 	decodedAddress, err := openkeyp2p.Base32Encoding.DecodeString(base32Str)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddressEncoding, err)
+	}
+
+	// Nicht kanonische Kodierungen (z.B. gesetzte Füllbits) werden abgelehnt
+	if openkeyp2p.Base32Encoding.EncodeToString(decodedAddress) != base32Str {
+		return nil, fmt.Errorf("%w: non canonical encoding", ErrInvalidAddressEncoding)
+	}
+
+	return _OpenKeyP2PAddressFromPlainBytes(decodedAddress)
+}
+
+// Dekodiert eine Adresse im versionierten Format
+func _OpenKeyP2PAddressDecodeFromBech32(adrString string) (*OpenKeyP2PAddress, error) {
+	data, err := _Bech32Decode(string(openkeyp2p.Prefix), adrString)
 	if err != nil {
 		return nil, err
 	}
+	if len(data) < 2 {
+		return nil, fmt.Errorf("%w: address too short", ErrInvalidAddressLength)
+	}
+	if data[0] != AddressFormatVersion0 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedAddressVersion, data[0])
+	}
 
-	newAddr := &OpenKeyP2PAddress{Prefix: openkeyp2p.Prefix, PubKey: decodedAddress[1:33]}
-	if !bytes.Equal(newAddr.ComputeChecksum(), decodedAddress[33:]) {
-		return nil, fmt.Errorf("checksum invalid")
+	keyType := openkeyp2p.OpenKeyP2PKeyType(data[1])
+	verifier, err := _GetAddressKeyTypeVerifier(keyType)
+	if err != nil {
+		return nil, err
 	}
 
-	return newAddr, nil
+	// Die erwartete Anzahl an Symbolen wird vor der Umwandlung geprüft
+	expectedSymbols := (verifier.PublicKeySize()*8 + 4) / 5
+	if len(data)-2 != expectedSymbols {
+		return nil, fmt.Errorf("%w: expected %d key symbols, got %d", ErrInvalidAddressLength, expectedSymbols, len(data)-2)
+	}
+
+	pubKey, err := _Bech32ConvertBits(data[2:], 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddressEncoding, err)
+	}
+
+	return _ValidateDecodedAddress(verifier, &OpenKeyP2PAddress{Prefix: openkeyp2p.Prefix, KeyType: keyType, PubKey: pubKey})
+}
+
+func _GetAddressKeyTypeVerifier(keyType openkeyp2p.OpenKeyP2PKeyType) (OpenKeyP2PVerifier, error) {
+	verifier, err := GetKeyTypeVerifier(keyType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownAddressKeyType, keyType)
+	}
+	return verifier, nil
+}
+
+// Prüft ob der dekodierte öffentliche Schlüssel für den Schlüsseltypen gültig ist
+func _ValidateDecodedAddress(verifier OpenKeyP2PVerifier, addr *OpenKeyP2PAddress) (*OpenKeyP2PAddress, error) {
+	if err := verifier.ValidatePublicKey(addr.PubKey); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddressKey, err)
+	}
+	return addr, nil
 }
 
 func OpenKeyP2PAddressFromPublicKey(pubKey ed25519.PublicKey) (*OpenKeyP2PAddress, error) {
+	return OpenKeyP2PAddressFromKey(openkeyp2p.Type_Ed25519, openkeyp2p.OpenKeyP2PPublicKey(pubKey))
+}
+
+// Erzeugt eine Adresse aus einem öffentlichen Schlüssel des angegebenen Typen
+func OpenKeyP2PAddressFromKey(keyType openkeyp2p.OpenKeyP2PKeyType, pubKey openkeyp2p.OpenKeyP2PPublicKey) (*OpenKeyP2PAddress, error) {
+	verifier, err := GetKeyTypeVerifier(keyType)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifier.ValidatePublicKey(pubKey); err != nil {
+		return nil, err
+	}
+
 	adrStruct := &OpenKeyP2PAddress{
-		Prefix: openkeyp2p.Prefix,
-		PubKey: openkeyp2p.OpenKeyP2PPublicKey(pubKey),
+		Prefix:  openkeyp2p.Prefix,
+		KeyType: keyType,
+		PubKey:  pubKey,
 	}
 	return adrStruct, nil
 }
 
-func AddressSign(privKey ed25519.PrivateKey, dataHash openkeyp2p.HashSlice) (OpenKeyP2PSignature, error) {
-	pubkey := PrivateKeyToPublicKey(privKey)
+// Gibt die Adresse eines privaten Schlüssels zurück
+func OpenKeyP2PAddressFromSigner(signer OpenKeyP2PSigner) (*OpenKeyP2PAddress, error) {
+	return OpenKeyP2PAddressFromKey(signer.KeyType(), signer.PublicKey())
+}
 
-	addr, err := OpenKeyP2PAddressFromPublicKey(pubkey)
+func AddressSign(signer OpenKeyP2PSigner, dataHash openkeyp2p.HashSlice) (OpenKeyP2PSignature, error) {
+	addr, err := OpenKeyP2PAddressFromSigner(signer)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +279,10 @@ func AddressSign(privKey ed25519.PrivateKey, dataHash openkeyp2p.HashSlice) (Ope
 		return nil, err
 	}
 
-	signSlcie := ed25519.Sign(privKey, dataAddressHashCombination)
+	signSlcie, err := signer.SignDigest(dataAddressHashCombination)
+	if err != nil {
+		return nil, err
+	}
 
 	return OpenKeyP2PSignature(signSlcie), nil
 }
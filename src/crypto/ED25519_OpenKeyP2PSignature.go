@@ -2,8 +2,10 @@ package crypto
 
 import (
 	"crypto/ed25519"
+	"crypto/sha512"
 	"fmt"
 
+	"filippo.io/edwards25519"
 	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
 )
 
@@ -23,5 +25,44 @@ func (o OpenKeyP2PSignature) VerifySignatureHashDigest(dataHash openkeyp2p.HashS
 	if o == nil {
 		return false, fmt.Errorf("nill signature")
 	}
-	return ed25519.Verify(ed25519.PublicKey(pubKey), dataHash, o), nil
+	return _VerifyEd25519ZIP215(pubKey, dataHash, o), nil
+}
+
+// Prüft eine ED25519 Signatur nach ZIP-215, dieselbe Regel wie bei der Stapelprüfung (SignatureBatch):
+//
+//	[8]([s]B - R - [k]A) == 0,  k = SHA-512(R || A || M) mod L
+//
+// A und R dürfen nicht kanonisch kodiert sein, s muss kanonisch sein. Im Gegensatz zu ed25519.Verify hängt das
+// Ergebnis damit nicht davon ab ob eine Signatur einzeln oder im Stapel geprüft wird
+func _VerifyEd25519ZIP215(pubKey []byte, message []byte, signature []byte) bool {
+	if len(pubKey) != ed25519.PublicKeySize || len(signature) != ed25519.SignatureSize {
+		return false
+	}
+
+	pubKeyPoint, err := new(edwards25519.Point).SetBytes(pubKey)
+	if err != nil {
+		return false
+	}
+	rPoint, err := new(edwards25519.Point).SetBytes(signature[:32])
+	if err != nil {
+		return false
+	}
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(signature[32:])
+	if err != nil {
+		return false
+	}
+
+	hasher := sha512.New()
+	hasher.Write(signature[:32])
+	hasher.Write(pubKey)
+	hasher.Write(message)
+	k, err := edwards25519.NewScalar().SetUniformBytes(hasher.Sum(nil))
+	if err != nil {
+		return false
+	}
+
+	// [s]B - [k]A - R
+	check := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(k, new(edwards25519.Point).Negate(pubKeyPoint), s)
+	check.Subtract(check, rPoint)
+	return check.MultByCofactor(check).Equal(edwards25519.NewIdentityPoint()) == 1
 }
@@ -0,0 +1,290 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// ALPN Protokoll welches von allen OpenKeyP2P Nodes verwendet wird
+const NodeTLSNextProto = "okp2p"
+
+// Domain Tag für die Signatur des TLS Schlüssels durch die Node Identität
+const nodeTLSIdentityDomain = "OpenKeyP2P-TLS-Identity:"
+
+// Zertifikatserweiterung welche die Node Identität an den TLS Schlüssel bindet, wird für Schlüsseltypen
+// verwendet welche TLS nicht direkt unterstützt
+var nodeTLSIdentityExtensionOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 62257, 1, 1}
+
+// Inhalt der Identitäts Erweiterung
+type _NodeTLSIdentityExtension struct {
+	Address   []byte `cbor:"1"`
+	Signature []byte `cbor:"2"`
+}
+
+// Erzeugt eine TLS Konfiguration deren Zertifikat an die Identität des Nodes gebunden ist,
+// sofern expectedPeer angegeben wurde, muss die Gegenseite genau diese Adresse besitzen.
+// Widerrufe werden gegen den Standard Store geprüft
+func GenerateNodeTLSConfig(signer OpenKeyP2PSigner, expectedPeer *OpenKeyP2PAddress) (*tls.Config, error) {
+	return GenerateNodeTLSConfigWithStore(defaultRevocationStore, signer, expectedPeer)
+}
+
+// Erzeugt eine TLS Konfiguration wie GenerateNodeTLSConfig, Widerrufe werden gegen store geprüft
+func GenerateNodeTLSConfigWithStore(store *RevocationStore, signer OpenKeyP2PSigner, expectedPeer *OpenKeyP2PAddress) (*tls.Config, error) {
+	addr, err := OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		return nil, err
+	}
+	addrString := addr.ToString()
+
+	// ED25519 Identitäten werden direkt als TLS Schlüssel verwendet, für alle anderen Schlüsseltypen
+	// wird ein temporärer ED25519 Schlüssel erzeugt welcher von der Identität signiert wird
+	var tlsPrivKey ed25519.PrivateKey
+	if edSigner, isEd25519 := signer.(Ed25519Signer); isEd25519 {
+		tlsPrivKey = ed25519.PrivateKey(edSigner)
+	} else {
+		_, tlsPrivKey, err = ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	notBefore := time.Now().Add(-1 * time.Hour)
+	notAfter := notBefore.Add(365 * 24 * time.Hour)
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	// Die Adresse wird als CommonName sowie als URI SAN eingetragen
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: addrString,
+		},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		URIs:                  []*url.URL{{Scheme: string(addr.Prefix), Opaque: addrString}},
+	}
+
+	// Sollte der TLS Schlüssel nicht der Identitätsschlüssel sein, wird er von der Identität signiert
+	if _, isEd25519 := signer.(Ed25519Signer); !isEd25519 {
+		identityExtension, err := _BuildNodeTLSIdentityExtension(signer, addr, PrivateKeyToPublicKey(tlsPrivKey))
+		if err != nil {
+			return nil, err
+		}
+		template.ExtraExtensions = []pkix.Extension{identityExtension}
+	}
+
+	// Das Zertifikat wird mit dem TLS Schlüssel selbst Signiert
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, PrivateKeyToPublicKey(tlsPrivKey), tlsPrivKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := tls.Certificate{
+		Certificate: [][]byte{certDER},
+		PrivateKey:  tlsPrivKey,
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+		NextProtos:   []string{NodeTLSNextProto},
+		ClientAuth:   tls.RequireAnyClientCert,
+		// Die Standardprüfung wird deaktiviert, da es keine CA gibt,
+		// die Identität wird stattdessen über VerifyPeerCertificate geprüft
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: VerifyPeerCertificateWithStore(store, expectedPeer),
+	}
+
+	// Als Listener wird die Identitäts Erweiterung eines Verbindenden nicht während des TLS Handshakes geprüft,
+	// der Node prüft sie erst nachdem der Verbindende den Arbeitsnachweis erbracht hat
+	if expectedPeer == nil {
+		tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			serverConfig := tlsConfig.Clone()
+			serverConfig.GetConfigForClient = nil
+			serverConfig.VerifyPeerCertificate = _VerifyPeerCertificate(store, nil, false)
+			return serverConfig, nil
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// Signiert den öffentlichen TLS Schlüssel mit der Node Identität
+func _BuildNodeTLSIdentityExtension(signer OpenKeyP2PSigner, addr *OpenKeyP2PAddress, tlsPubKey ed25519.PublicKey) (pkix.Extension, error) {
+	spki, err := x509.MarshalPKIXPublicKey(tlsPubKey)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+
+	spkiHash, err := ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, []byte(nodeTLSIdentityDomain), spki)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+
+	signature, err := AddressSign(signer, spkiHash)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+
+	value, err := cbor.Marshal(&_NodeTLSIdentityExtension{Address: addr.ToByteSlice(), Signature: signature})
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+
+	return pkix.Extension{Id: nodeTLSIdentityExtensionOID, Value: value}, nil
+}
+
+// Erzeugt eine Prüffunktion für tls.Config.VerifyPeerCertificate, diese leitet die Adresse der Gegenseite aus
+// dem Zertifikat ab und vergleicht sie mit expectedPeer, ist expectedPeer nil wird jede gültige Identität akzeptiert.
+// Im Standard Store widerrufene Identitäten werden abgelehnt
+func VerifyPeerCertificate(expectedPeer *OpenKeyP2PAddress) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return VerifyPeerCertificateWithStore(defaultRevocationStore, expectedPeer)
+}
+
+// Erzeugt eine Prüffunktion wie VerifyPeerCertificate, in store widerrufene Identitäten werden abgelehnt
+func VerifyPeerCertificateWithStore(store *RevocationStore, expectedPeer *OpenKeyP2PAddress) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return _VerifyPeerCertificate(store, expectedPeer, true)
+}
+
+// Ist verifyIdentity false, wird bei Zertifikaten mit Identitäts Erweiterung nur das Zertifikat selbst geprüft,
+// die Identität muss danach per PeerAddressFromCertificate geprüft werden
+func _VerifyPeerCertificate(store *RevocationStore, expectedPeer *OpenKeyP2PAddress, verifyIdentity bool) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(rawCerts) != 1 {
+			return fmt.Errorf("%w: expected exactly one certificate, got %d", ErrInvalidPeerCertificate, len(rawCerts))
+		}
+
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidPeerCertificate, err)
+		}
+
+		if !verifyIdentity && _FindNodeTLSIdentityExtension(cert) != nil {
+			_, err := _CheckNodeCertificate(cert)
+			return err
+		}
+
+		peerAddr, err := PeerAddressFromCertificateWithStore(store, cert)
+		if err != nil {
+			return err
+		}
+
+		if store.IsAddressRevoked(peerAddr) {
+			return fmt.Errorf("%w: %s", ErrAddressRevoked, peerAddr.ToString())
+		}
+
+		if expectedPeer != nil && !expectedPeer.Equal(peerAddr) {
+			return fmt.Errorf("%w: got %s, expected %s", ErrPeerIdentityMismatch, peerAddr.ToString(), expectedPeer.ToString())
+		}
+
+		return nil
+	}
+}
+
+// Leitet die OpenKeyP2P Adresse aus einem selbst signierten Node Zertifikat ab, die Signatur einer
+// Identitäts Erweiterung wird gegen den Standard Store geprüft
+func PeerAddressFromCertificate(cert *x509.Certificate) (*OpenKeyP2PAddress, error) {
+	return PeerAddressFromCertificateWithStore(defaultRevocationStore, cert)
+}
+
+// Leitet die OpenKeyP2P Adresse wie PeerAddressFromCertificate ab, die Signatur einer Identitäts
+// Erweiterung wird gegen store geprüft
+func PeerAddressFromCertificateWithStore(store *RevocationStore, cert *x509.Certificate) (*OpenKeyP2PAddress, error) {
+	pubKey, err := _CheckNodeCertificate(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sofern eine Identitäts Erweiterung vorhanden ist, wird die Adresse aus dieser gelesen,
+	// andernfalls ist der TLS Schlüssel selbst die Identität
+	var peerAddr *OpenKeyP2PAddress
+	if identityExtension := _FindNodeTLSIdentityExtension(cert); identityExtension != nil {
+		peerAddr, err = _VerifyNodeTLSIdentityExtension(store, identityExtension, cert.RawSubjectPublicKeyInfo)
+	} else {
+		peerAddr, err = OpenKeyP2PAddressFromPublicKey(pubKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Sofern ein CommonName angegeben wurde, muss dieser mit der Adresse übereinstimmen (neues oder altes Format)
+	commonName := cert.Subject.CommonName
+	if commonName != "" && commonName != peerAddr.ToString() && commonName != peerAddr.ToLegacyString() {
+		return nil, fmt.Errorf("%w: common name does not match the public key", ErrInvalidPeerCertificate)
+	}
+
+	return peerAddr, nil
+}
+
+// Prüft ob das Zertifikat mit dem eigenen ED25519 Schlüssel signiert und gültig ist, gibt den TLS Schlüssel zurück
+func _CheckNodeCertificate(cert *x509.Certificate) (ed25519.PublicKey, error) {
+	pubKey, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: public key is not ed25519", ErrInvalidPeerCertificate)
+	}
+
+	// Das Zertifikat muss mit dem eigenen Schlüssel signiert sein
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPeerCertificate, err)
+	}
+
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, fmt.Errorf("%w: certificate expired or not yet valid", ErrInvalidPeerCertificate)
+	}
+	return pubKey, nil
+}
+
+func _FindNodeTLSIdentityExtension(cert *x509.Certificate) []byte {
+	for _, extension := range cert.Extensions {
+		if extension.Id.Equal(nodeTLSIdentityExtensionOID) {
+			return extension.Value
+		}
+	}
+	return nil
+}
+
+// Prüft ob der TLS Schlüssel von der in der Erweiterung angegebenen Identität signiert wurde
+func _VerifyNodeTLSIdentityExtension(store *RevocationStore, value []byte, spki []byte) (*OpenKeyP2PAddress, error) {
+	var identityExtension _NodeTLSIdentityExtension
+	if err := cbor.Unmarshal(value, &identityExtension); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPeerCertificate, err)
+	}
+
+	peerAddr, err := OpenKeyP2PAddressDecodeFromByteSlice(identityExtension.Address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPeerCertificate, err)
+	}
+
+	spkiHash, err := ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, []byte(nodeTLSIdentityDomain), spki)
+	if err != nil {
+		return nil, err
+	}
+
+	isValid, err := peerAddr.VerifySignatureWithStore(store, OpenKeyP2PSignature(identityExtension.Signature), spkiHash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPeerCertificate, err)
+	}
+	if !isValid {
+		return nil, fmt.Errorf("%w: invalid identity signature", ErrInvalidPeerCertificate)
+	}
+
+	return peerAddr, nil
+}
@@ -13,6 +13,8 @@ import (
 	"time"
 )
 
+// Deprecated: Das Zertifikat ist nicht an die Node Identität gebunden und wird von Nodes abgelehnt,
+// stattdessen GenerateNodeTLSConfig verwenden.
 func GenerateTempTLSConfig() (*tls.Config, error) {
 	// Generiere den privaten Schlüssel für ECDSA (P-256)
 	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
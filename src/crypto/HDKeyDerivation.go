@@ -0,0 +1,176 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Hierarchische Ableitung von ED25519 Schlüsseln nach SLIP-0010, für ED25519 sind nur gehärtete Indizes erlaubt.
+//
+//	Master: I = HMAC-SHA512("ed25519 seed", seed)
+//	Kind:   I = HMAC-SHA512(chain code, 0x00 || key || ser32(index))
+//
+// Die linke Hälfte von I ist der Seed des Schlüssels, die rechte Hälfte der neue Chain Code.
+
+const (
+	HardenedKeyOffset uint32 = 0x80000000
+
+	// Nicht registrierter Coin Type ("ok") für den Standardpfad m/44'/28523'/account'/index'
+	OpenKeyP2PDerivationPurpose  uint32 = 44
+	OpenKeyP2PDerivationCoinType uint32 = 0x6f6b
+
+	slip10Ed25519Curve = "ed25519 seed"
+)
+
+// Stellt einen Ableitungspfad dar, alle Indizes enthalten den HardenedKeyOffset
+type DerivationPath []uint32
+
+// Abgeleiteter Schlüssel inklusive Chain Code und Pfad
+type HDKey struct {
+	seed      []byte
+	chainCode []byte
+	path      DerivationPath
+}
+
+// Gibt den Standardpfad für einen Dienst zurück
+func DefaultDerivationPath(account uint32, index uint32) DerivationPath {
+	return DerivationPath{
+		OpenKeyP2PDerivationPurpose | HardenedKeyOffset,
+		OpenKeyP2PDerivationCoinType | HardenedKeyOffset,
+		account | HardenedKeyOffset,
+		index | HardenedKeyOffset,
+	}
+}
+
+// Liest einen Pfad der Form m/44'/28523'/0'/1' ein, "h" und "H" werden ebenfalls als gehärtet akzeptiert
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	segments := strings.Split(strings.TrimSpace(path), "/")
+	if len(segments) == 0 || segments[0] != "m" {
+		return nil, fmt.Errorf("%w: path must start with m", ErrInvalidDerivationPath)
+	}
+
+	reval := make(DerivationPath, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		trimmed := strings.TrimRight(segment, "'hH")
+		if len(segment)-len(trimmed) != 1 {
+			return nil, fmt.Errorf("%w: %q, ed25519 supports only hardened derivation", ErrInvalidDerivationPath, segment)
+		}
+		index, err := strconv.ParseUint(trimmed, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidDerivationPath, segment)
+		}
+		reval = append(reval, uint32(index)|HardenedKeyOffset)
+	}
+	return reval, nil
+}
+
+// Gibt den Pfad in der Form m/44'/28523'/0'/1' zurück
+func (o DerivationPath) String() string {
+	var builder strings.Builder
+	builder.WriteString("m")
+	for _, index := range o {
+		builder.WriteString("/")
+		builder.WriteString(strconv.FormatUint(uint64(index&^HardenedKeyOffset), 10))
+		if index&HardenedKeyOffset != 0 {
+			builder.WriteString("'")
+		}
+	}
+	return builder.String()
+}
+
+// Erzeugt den Master Schlüssel aus einem Master Seed (16 bis 64 Bytes)
+func NewMasterHDKey(masterSeed []byte) (*HDKey, error) {
+	if len(masterSeed) < 16 || len(masterSeed) > 64 {
+		return nil, fmt.Errorf("invalid master seed size %d", len(masterSeed))
+	}
+
+	mac := hmac.New(sha512.New, []byte(slip10Ed25519Curve))
+	mac.Write(masterSeed)
+	digest := mac.Sum(nil)
+
+	return &HDKey{seed: digest[:32], chainCode: digest[32:], path: DerivationPath{}}, nil
+}
+
+// Leitet einen gehärteten Kindschlüssel ab
+func (o *HDKey) DeriveChild(index uint32) (*HDKey, error) {
+	if index&HardenedKeyOffset == 0 {
+		return nil, fmt.Errorf("%w: index %d is not hardened", ErrInvalidDerivationPath, index)
+	}
+
+	data := make([]byte, 0, 1+32+4)
+	data = append(data, 0x00)
+	data = append(data, o.seed...)
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, o.chainCode)
+	mac.Write(data)
+	digest := mac.Sum(nil)
+
+	path := make(DerivationPath, 0, len(o.path)+1)
+	path = append(path, o.path...)
+	path = append(path, index)
+
+	return &HDKey{seed: digest[:32], chainCode: digest[32:], path: path}, nil
+}
+
+// Leitet den Schlüssel für einen relativen Pfad ab
+func (o *HDKey) Derive(path DerivationPath) (*HDKey, error) {
+	current := o
+	for _, index := range path {
+		child, err := current.DeriveChild(index)
+		if err != nil {
+			return nil, err
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// Gibt den 32 Byte Seed zurück, dieser kann an GenerateKeyPairFromSeed übergeben werden
+func (o *HDKey) Seed() []byte {
+	reval := make([]byte, len(o.seed))
+	copy(reval, o.seed)
+	return reval
+}
+
+// Gibt den Chain Code zurück
+func (o *HDKey) ChainCode() []byte {
+	reval := make([]byte, len(o.chainCode))
+	copy(reval, o.chainCode)
+	return reval
+}
+
+// Gibt den Pfad ausgehend vom Master Schlüssel zurück
+func (o *HDKey) Path() DerivationPath {
+	return append(DerivationPath{}, o.path...)
+}
+
+// Gibt den ED25519 Signer des abgeleiteten Schlüssels zurück
+func (o *HDKey) Signer() Ed25519Signer {
+	privKey, _ := GenerateKeyPairFromSeed(o.seed)
+	return Ed25519Signer(privKey)
+}
+
+// Gibt die Adresse des abgeleiteten Schlüssels zurück
+func (o *HDKey) Address() (*OpenKeyP2PAddress, error) {
+	privKey, _ := GenerateKeyPairFromSeed(o.seed)
+	return OpenKeyP2PAddressFromPublicKey(privKey.Public().(ed25519.PublicKey))
+}
+
+// Leitet den Signer für einen Pfad direkt aus dem Master Seed ab
+func DeriveEd25519Signer(masterSeed []byte, path DerivationPath) (Ed25519Signer, error) {
+	masterKey, err := NewMasterHDKey(masterSeed)
+	if err != nil {
+		return nil, err
+	}
+	childKey, err := masterKey.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	return childKey.Signer(), nil
+}
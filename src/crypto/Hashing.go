@@ -3,9 +3,11 @@ package crypto
 import (
 	"crypto/sha256"
 	"fmt"
+	"hash"
 
 	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
 	"golang.org/x/crypto/sha3"
+	"lukechampine.com/blake3"
 )
 
 func ComputeSha256BitHash(data []byte) openkeyp2p.HashSlice {
@@ -18,17 +20,33 @@ func ComputeSha3_256BitHash(data []byte) openkeyp2p.HashSlice {
 	return hash[:]            // Umwandlung in []byte
 }
 
-func ComputeHash(algo openkeyp2p.HashAlgorithm, data ...[]byte) (openkeyp2p.HashSlice, error) {
-	byteBlock := make([]byte, 0)
-	for _, item := range data {
-		byteBlock = append(byteBlock, item...)
-	}
+func ComputeBlake3_256BitHash(data []byte) openkeyp2p.HashSlice {
+	hash := blake3.Sum256(data) // Gibt ein [32]byte-Array zurück
+	return hash[:]              // Umwandlung in []byte
+}
+
+// Erzeugt einen Streaming Hasher für den angegebenen Algorithmus, große Eingaben können so schrittweise gehasht werden
+func NewHash(algo openkeyp2p.HashAlgorithm) (hash.Hash, error) {
 	switch algo {
 	case openkeyp2p.SHA_256:
-		return ComputeSha256BitHash(byteBlock), nil
+		return sha256.New(), nil
 	case openkeyp2p.SHA3_256:
-		return ComputeSha3_256BitHash(byteBlock), nil
+		return sha3.New256(), nil
+	case openkeyp2p.BLAKE3_256:
+		return blake3.New(32, nil), nil
 	default:
 		return nil, fmt.Errorf("unsupported hashing methode")
 	}
 }
+
+// Hasht alle Eingaben nacheinander, ohne sie vorher in einen gemeinsamen Puffer zu kopieren
+func ComputeHash(algo openkeyp2p.HashAlgorithm, data ...[]byte) (openkeyp2p.HashSlice, error) {
+	hasher, err := NewHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range data {
+		hasher.Write(item)
+	}
+	return hasher.Sum(nil), nil
+}
@@ -0,0 +1,78 @@
+package crypto
+
+import (
+	"fmt"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// Stellt den privaten Schlüssel einer Identität dar, unabhängig vom verwendeten Schlüsseltypen
+type OpenKeyP2PSigner interface {
+	// Gibt den Schlüsseltypen zurück
+	KeyType() openkeyp2p.OpenKeyP2PKeyType
+
+	// Gibt den öffentlichen Schlüssel im Adressformat des Schlüsseltypen zurück
+	PublicKey() openkeyp2p.OpenKeyP2PPublicKey
+
+	// Signiert einen Hash Digest
+	SignDigest(digest []byte) ([]byte, error)
+
+	// Gibt den privaten Curve25519 Schlüssel zurück welcher für den X25519 Schlüsselaustausch verwendet wird
+	CurvePrivateKey() ([]byte, error)
+}
+
+// Prüft öffentliche Schlüssel und Signaturen eines Schlüsseltypen
+type OpenKeyP2PVerifier interface {
+	// Gibt den Schlüsseltypen zurück
+	KeyType() openkeyp2p.OpenKeyP2PKeyType
+
+	// Gibt die Größe eines öffentlichen Schlüssels in Bytes zurück
+	PublicKeySize() int
+
+	// Prüft ob ein öffentlicher Schlüssel gültig ist
+	ValidatePublicKey(pubKey openkeyp2p.OpenKeyP2PPublicKey) error
+
+	// Prüft eine Signatur über einen Hash Digest
+	VerifyDigest(pubKey openkeyp2p.OpenKeyP2PPublicKey, digest []byte, signature []byte) (bool, error)
+
+	// Erzeugt aus einem 32 Byte Seed einen privaten Schlüssel
+	SignerFromSeed(seed []byte) (OpenKeyP2PSigner, error)
+}
+
+var keyTypeVerifiers = map[openkeyp2p.OpenKeyP2PKeyType]OpenKeyP2PVerifier{
+	openkeyp2p.Type_Ed25519:   ed25519Verifier{},
+	openkeyp2p.Type_Secp256k1: secp256k1Verifier{},
+	openkeyp2p.Type_BLS12381:  bls12381Verifier{},
+}
+
+// Gibt den Verifier für einen Schlüsseltypen zurück
+func GetKeyTypeVerifier(keyType openkeyp2p.OpenKeyP2PKeyType) (OpenKeyP2PVerifier, error) {
+	verifier, found := keyTypeVerifiers[keyType]
+	if !found {
+		return nil, fmt.Errorf("unknown key type %d", keyType)
+	}
+	return verifier, nil
+}
+
+// Erzeugt aus einem 32 Byte Seed einen privaten Schlüssel des angegebenen Typen
+func NewSignerFromSeed(keyType openkeyp2p.OpenKeyP2PKeyType, seed []byte) (OpenKeyP2PSigner, error) {
+	verifier, err := GetKeyTypeVerifier(keyType)
+	if err != nil {
+		return nil, err
+	}
+	return verifier.SignerFromSeed(seed)
+}
+
+// Gibt den Namen eines Schlüsseltypen zurück
+func KeyTypeName(keyType openkeyp2p.OpenKeyP2PKeyType) string {
+	switch keyType {
+	case openkeyp2p.Type_Ed25519:
+		return "ed25519"
+	case openkeyp2p.Type_Secp256k1:
+		return "secp256k1"
+	case openkeyp2p.Type_BLS12381:
+		return "bls12381"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,171 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Mnemonic Sicherung eines 32 Byte Seeds mit der BIP-39 Kodierung (englische Wortliste):
+//
+//	256 Bit Entropie + 8 Bit Prüfsumme (erstes Byte von SHA-256(Entropie)) = 24 Wörter zu je 11 Bit
+//
+// Die Entropie der Wörter ist der Seed selbst, jede vorhandene Identität kann somit als Mnemonic gesichert
+// werden und die Wörter hängen nie von der Passphrase ab. Die optionale Passphrase ist eine zusätzliche Schicht:
+// mit Passphrase sind die ersten 32 Byte des BIP-39 Seeds (PBKDF2-HMAC-SHA512, Salt "mnemonic" + Passphrase,
+// 2048 Iterationen) der Seed des Schlüssels, jede Passphrase ergibt somit eine andere, gültige Identität.
+
+const (
+	MnemonicWordCount = 24
+
+	mnemonicMinEntropySize = 16
+	mnemonicSeedSize       = 32
+	mnemonicSaltPrefix     = "mnemonic"
+	mnemonicBIP39Iters     = 2048
+	mnemonicBIP39SeedSize  = 64
+)
+
+//go:embed mnemonic_english.txt
+var mnemonicEnglishWordlist string
+
+var mnemonicWords, mnemonicWordIndex = func() ([]string, map[string]int) {
+	words := strings.Fields(mnemonicEnglishWordlist)
+	if len(words) != 2048 {
+		panic("invalid mnemonic wordlist")
+	}
+	index := make(map[string]int, len(words))
+	for i, word := range words {
+		index[word] = i
+	}
+	return words, index
+}()
+
+// Erzeugt einen neuen zufälligen Seed und gibt ihn zusammen mit seiner Mnemonic zurück
+func NewRandomMnemonic(passphrase string) (string, []byte, error) {
+	entropy := make([]byte, mnemonicSeedSize)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", nil, err
+	}
+
+	mnemonic, err := EntropyToMnemonic(entropy)
+	if err != nil {
+		return "", nil, err
+	}
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return "", nil, err
+	}
+	return mnemonic, seed, nil
+}
+
+// Wandelt einen 32 Byte Seed in eine Mnemonic aus 24 Wörtern um, ohne Passphrase ergibt MnemonicToSeed wieder den Seed
+func SeedToMnemonic(seed []byte) (string, error) {
+	if len(seed) != mnemonicSeedSize {
+		return "", fmt.Errorf("invalid seed size %d", len(seed))
+	}
+	return EntropyToMnemonic(seed)
+}
+
+// Wandelt 16 bis 32 Byte Entropie (Vielfaches von 4 Byte) in eine Mnemonic aus 12 bis 24 Wörtern um
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < mnemonicMinEntropySize || len(entropy) > mnemonicSeedSize || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("invalid entropy size %d", len(entropy))
+	}
+	checksum := sha256.Sum256(entropy)
+
+	// Entropie und Prüfsumme werden in 11 Bit Gruppen aufgeteilt, die Prüfsumme passt immer in das erste Byte
+	bits := append(append([]byte{}, entropy...), checksum[0])
+	words := make([]string, len(entropy)*3/4)
+	for i := range words {
+		index := 0
+		for bit := i * 11; bit < (i+1)*11; bit++ {
+			index = index<<1 | int(bits[bit/8]>>(7-bit%8)&1)
+		}
+		words[i] = mnemonicWords[index]
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// Wandelt eine Mnemonic zurück in ihre Entropie, Wortanzahl, Wörter und Prüfsumme werden geprüft
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := _MnemonicWords(mnemonic)
+	if len(words) < 12 || len(words) > MnemonicWordCount || len(words)%3 != 0 {
+		return nil, fmt.Errorf("%w: expected 12 to %d words, got %d", ErrInvalidMnemonicLength, MnemonicWordCount, len(words))
+	}
+
+	entropySize := len(words) * 4 / 3
+	checksumBits := entropySize * 8 / 32
+	bits := make([]byte, entropySize+1)
+	for i, word := range words {
+		index, found := mnemonicWordIndex[word]
+		if !found {
+			return nil, fmt.Errorf("%w: %q at position %d", ErrInvalidMnemonicWord, word, i+1)
+		}
+		for bit := 0; bit < 11; bit++ {
+			if index>>(10-bit)&1 == 1 {
+				pos := i*11 + bit
+				bits[pos/8] |= 1 << (7 - pos%8)
+			}
+		}
+	}
+
+	entropy := bits[:entropySize]
+	checksum := sha256.Sum256(entropy)
+	if checksum[0]>>(8-checksumBits) != bits[entropySize]>>(8-checksumBits) {
+		return nil, ErrInvalidMnemonicChecksum
+	}
+	return entropy, nil
+}
+
+// Wandelt eine Mnemonic aus 24 Wörtern zurück in den Seed, mit Passphrase wird der Seed aus dem BIP-39 Seed abgeleitet
+func MnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	entropy, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	if len(entropy) != mnemonicSeedSize {
+		return nil, fmt.Errorf("%w: expected %d words, got %d", ErrInvalidMnemonicLength, MnemonicWordCount, len(_MnemonicWords(mnemonic)))
+	}
+	if passphrase == "" {
+		return entropy, nil
+	}
+	return _MnemonicBIP39Seed(mnemonic, passphrase)[:mnemonicSeedSize], nil
+}
+
+// Erzeugt den privaten Schlüssel des angegebenen Typen aus einer Mnemonic
+func SignerFromMnemonic(mnemonic string, passphrase string, keyType openkeyp2p.OpenKeyP2PKeyType) (OpenKeyP2PSigner, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerFromSeed(keyType, seed)
+}
+
+// Stellt die Adresse eines Nodes allein aus seiner Mnemonic wieder her
+func RestoreAddressFromMnemonic(mnemonic string, passphrase string, keyType openkeyp2p.OpenKeyP2PKeyType) (*OpenKeyP2PAddress, error) {
+	signer, err := SignerFromMnemonic(mnemonic, passphrase, keyType)
+	if err != nil {
+		return nil, err
+	}
+	return OpenKeyP2PAddressFromSigner(signer)
+}
+
+// Normalisiert die Mnemonic (NFKD, Kleinschreibung, einfache Leerzeichen) und teilt sie in Wörter auf
+func _MnemonicWords(mnemonic string) []string {
+	return strings.Fields(strings.ToLower(norm.NFKD.String(mnemonic)))
+}
+
+// Leitet den 64 Byte BIP-39 Seed aus einer bereits geprüften Mnemonic ab
+func _MnemonicBIP39Seed(mnemonic string, passphrase string) []byte {
+	password := []byte(strings.Join(_MnemonicWords(mnemonic), " "))
+	salt := []byte(mnemonicSaltPrefix + norm.NFKD.String(passphrase))
+	return pbkdf2.Key(password, salt, mnemonicBIP39Iters, mnemonicBIP39SeedSize, sha512.New)
+}
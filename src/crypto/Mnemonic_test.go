@@ -0,0 +1,118 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// Testvektoren aus der BIP-39 Referenzimplementierung, Passphrase "TREZOR"
+var mnemonicTestVectors = []struct {
+	entropy  string
+	mnemonic string
+	seed     string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		strings.Repeat("abandon ", 23) + "art",
+		"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		strings.Repeat("zoo ", 23) + "vote",
+		"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+	},
+}
+
+func TestMnemonicBIP39Vectors(t *testing.T) {
+	for _, vector := range mnemonicTestVectors {
+		entropy, _ := hex.DecodeString(vector.entropy)
+		mnemonic, err := EntropyToMnemonic(entropy)
+		if err != nil || mnemonic != vector.mnemonic {
+			t.Fatalf("entropy %s encoded as %q: %v", vector.entropy, mnemonic, err)
+		}
+
+		decoded, err := MnemonicToEntropy(vector.mnemonic)
+		if err != nil || !bytes.Equal(decoded, entropy) {
+			t.Fatalf("mnemonic %q decoded as %x: %v", vector.mnemonic, decoded, err)
+		}
+
+		if seed := _MnemonicBIP39Seed(vector.mnemonic, "TREZOR"); hex.EncodeToString(seed) != vector.seed {
+			t.Fatalf("mnemonic %q derived seed %x", vector.mnemonic, seed)
+		}
+	}
+}
+
+// Der Seed einer vorhandenen Identität muss sich als Mnemonic sichern und ohne Passphrase wiederherstellen lassen
+func TestMnemonicRestoresExistingSeed(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x5a}, 32)
+	signer, err := NewSignerFromSeed(openkeyp2p.Type_Ed25519, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mnemonic, err := SeedToMnemonic(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := MnemonicToSeed(mnemonic, "")
+	if err != nil || !bytes.Equal(restored, seed) {
+		t.Fatalf("seed restored as %x: %v", restored, err)
+	}
+	restoredAddress, err := RestoreAddressFromMnemonic(mnemonic, "", openkeyp2p.Type_Ed25519)
+	if err != nil || !restoredAddress.Equal(address) {
+		t.Fatalf("address not restored: %v", err)
+	}
+
+	// 12 Wörter sind gültiges BIP-39, enthalten aber keinen vollständigen Seed
+	if _, err := MnemonicToSeed(mnemonicTestVectors[0].mnemonic, ""); !errors.Is(err, ErrInvalidMnemonicLength) {
+		t.Fatalf("short mnemonic accepted as seed: %v", err)
+	}
+}
+
+// Die Passphrase darf nur den Seed verändern, niemals die Wörter oder deren Prüfung
+func TestMnemonicPassphraseOnlyChangesSeed(t *testing.T) {
+	mnemonic, seed, err := NewRandomMnemonic("passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutPassphrase, err := RestoreAddressFromMnemonic(mnemonic, "", openkeyp2p.Type_Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withPassphrase, err := RestoreAddressFromMnemonic(mnemonic, "passphrase", openkeyp2p.Type_Ed25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withoutPassphrase.Equal(withPassphrase) {
+		t.Fatal("passphrase did not change the identity")
+	}
+	if signer, err := NewSignerFromSeed(openkeyp2p.Type_Ed25519, seed); err != nil {
+		t.Fatal(err)
+	} else if address, _ := OpenKeyP2PAddressFromSigner(signer); !address.Equal(withPassphrase) {
+		t.Fatal("returned seed does not match the restored identity")
+	}
+
+	manipulated := strings.Repeat("abandon ", 23) + "zoo"
+	if _, err := MnemonicToSeed(manipulated, "passphrase"); !errors.Is(err, ErrInvalidMnemonicChecksum) {
+		t.Fatalf("manipulated mnemonic not rejected: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// Multihash Codes aus der Multicodec Tabelle
+const (
+	MultihashCodeSHA2_256   uint64 = 0x12
+	MultihashCodeSHA3_256   uint64 = 0x16
+	MultihashCodeBLAKE3_256 uint64 = 0x1e
+)
+
+// Gibt den Multihash Code eines Hash Algorithmus zurück
+func MultihashCode(algo openkeyp2p.HashAlgorithm) (uint64, error) {
+	switch algo {
+	case openkeyp2p.SHA_256:
+		return MultihashCodeSHA2_256, nil
+	case openkeyp2p.SHA3_256:
+		return MultihashCodeSHA3_256, nil
+	case openkeyp2p.BLAKE3_256:
+		return MultihashCodeBLAKE3_256, nil
+	default:
+		return 0, fmt.Errorf("unsupported hashing methode")
+	}
+}
+
+// Gibt den Hash Algorithmus zu einem Multihash Code zurück
+func HashAlgorithmFromMultihashCode(code uint64) (openkeyp2p.HashAlgorithm, error) {
+	switch code {
+	case MultihashCodeSHA2_256:
+		return openkeyp2p.SHA_256, nil
+	case MultihashCodeSHA3_256:
+		return openkeyp2p.SHA3_256, nil
+	case MultihashCodeBLAKE3_256:
+		return openkeyp2p.BLAKE3_256, nil
+	default:
+		return 0, fmt.Errorf("unsupported multihash code 0x%x", code)
+	}
+}
+
+// Kodiert einen Hash zusammen mit seinem Algorithmus als Multihash (varint Code, varint Länge, Digest)
+func EncodeMultihash(algo openkeyp2p.HashAlgorithm, digest openkeyp2p.HashSlice) (openkeyp2p.Multihash, error) {
+	code, err := MultihashCode(algo)
+	if err != nil {
+		return nil, err
+	}
+
+	reval := make([]byte, 0, 2*binary.MaxVarintLen64+len(digest))
+	reval = binary.AppendUvarint(reval, code)
+	reval = binary.AppendUvarint(reval, uint64(len(digest)))
+	reval = append(reval, digest...)
+	return openkeyp2p.Multihash(reval), nil
+}
+
+// Zerlegt einen Multihash in Algorithmus und Digest
+func DecodeMultihash(multihash openkeyp2p.Multihash) (openkeyp2p.HashAlgorithm, openkeyp2p.HashSlice, error) {
+	code, codeSize := binary.Uvarint(multihash)
+	if codeSize <= 0 {
+		return 0, nil, fmt.Errorf("invalid multihash code")
+	}
+
+	length, lengthSize := binary.Uvarint(multihash[codeSize:])
+	if lengthSize <= 0 {
+		return 0, nil, fmt.Errorf("invalid multihash length")
+	}
+
+	digest := multihash[codeSize+lengthSize:]
+	if uint64(len(digest)) != length {
+		return 0, nil, fmt.Errorf("multihash length mismatch")
+	}
+
+	algo, err := HashAlgorithmFromMultihashCode(code)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return algo, openkeyp2p.HashSlice(bytes.Clone(digest)), nil
+}
+
+// Berechnet einen Hash und gibt ihn direkt als Multihash zurück
+func ComputeMultihash(algo openkeyp2p.HashAlgorithm, data ...[]byte) (openkeyp2p.Multihash, error) {
+	digest, err := ComputeHash(algo, data...)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeMultihash(algo, digest)
+}
+
+// Prüft ob der Multihash zu den Daten passt, der Algorithmus wird dem Multihash entnommen
+func VerifyMultihash(multihash openkeyp2p.Multihash, data ...[]byte) (bool, error) {
+	algo, digest, err := DecodeMultihash(multihash)
+	if err != nil {
+		return false, err
+	}
+
+	computed, err := ComputeHash(algo, data...)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(computed, digest), nil
+}
@@ -0,0 +1,366 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Aufbau eines verschlüsselten Payload Frames:
+//
+//	version   uint8   PayloadFrameVersion2
+//	session   [8]byte Zufällige Sitzungs ID des Absenders
+//	timestamp int64   Sendezeitpunkt in Millisekunden seit 1970 (BE)
+//	counter   uint64  Nachrichtenzähler des Absenders (BE), bildet die Nonce
+//	cipher    []byte  ChaCha20-Poly1305 Ciphertext inklusive Tag
+//
+// Der X25519 Schlüssel zweier Adressen ist immer identisch, daher wählt jeder Absender eine zufällige
+// Sitzungs ID welche in die Schlüsselableitung einfließt, so wird eine Wiederverwendung von Nonces verhindert.
+// Version, Sitzungs ID, Zeitpunkt und Zähler werden zusammen mit den Additional Data des Aufrufers authentifiziert.
+//
+// Frames deren Zeitpunkt mehr als payloadMaxFrameAge abweicht werden abgelehnt. Wird der Empfangsstatus einer
+// Sitzungs ID verdrängt, bleibt ihr höchster Zähler als Replay Eintrag erhalten bis alle Frames vor der
+// Verdrängung veraltet sind, so können aufgezeichnete Frames auch nach der Verdrängung nicht wiederholt werden.
+
+const (
+	PayloadFrameVersion2 uint8 = 2
+
+	payloadSessionIdSize    = 8
+	payloadFrameHeaderSize  = 1 + payloadSessionIdSize + 8 + 8
+	payloadReplayWindow     = 64
+	payloadMaxRecvSessions  = 32
+	payloadMaxReplayRecords = 4 * payloadMaxRecvSessions
+	payloadKeyScheduleLabel = "OpenKeyP2P-E2E-v2"
+
+	// Maximale Abweichung des Sendezeitpunkts von der lokalen Zeit
+	payloadMaxFrameAge = 2 * time.Minute
+
+	// Ein Frame vor der Verdrängung kann bei maximaler Uhrenabweichung noch bis zu 2*payloadMaxFrameAge
+	// nach der Verdrängung gültig sein, solange bleibt der Replay Eintrag erhalten
+	payloadReplayRecordLifetime = 2 * payloadMaxFrameAge
+)
+
+// Anzahl der Bytes welche ein Frame zusätzlich zum Klartext belegt
+const PayloadFrameOverhead = payloadFrameHeaderSize + chacha20poly1305.Overhead
+
+// Stellt eine Ende-zu-Ende verschlüsselte Sitzung zwischen zwei OpenKeyP2P Adressen dar
+type PayloadSession struct {
+	sharedSecret    []byte
+	firstAddr       []byte
+	secondAddr      []byte
+	localIsFirst    bool
+	sendLock        *sync.Mutex
+	sendSessionId   [payloadSessionIdSize]byte
+	sendAEAD        cipher.AEAD
+	sendCounter     uint64
+	recvLock        *sync.Mutex
+	recvSessions    map[[payloadSessionIdSize]byte]*_PayloadRecvState
+	recvSessionList [][payloadSessionIdSize]byte
+	replayRecords   map[[payloadSessionIdSize]byte]_PayloadReplayRecord
+}
+
+// Höchster Zähler einer verdrängten Sitzungs ID
+type _PayloadReplayRecord struct {
+	highest   uint64
+	expiresAt time.Time
+}
+
+// Replay Einträge einer verworfenen Sitzung, damit eine neue Sitzung zur selben Adresse sie übernehmen kann
+type PayloadReplayState struct {
+	records map[[payloadSessionIdSize]byte]_PayloadReplayRecord
+}
+
+// Empfangsstatus für eine Sitzungs ID der Gegenseite
+type _PayloadRecvState struct {
+	aead     cipher.AEAD
+	highest  uint64
+	bitmap   uint64
+	received bool
+}
+
+// Erzeugt eine Sitzung, der öffentliche Curve25519 Schlüssel wird aus der Adresse der Gegenseite abgeleitet
+func NewPayloadSession(localSigner OpenKeyP2PSigner, remote *OpenKeyP2PAddress) (*PayloadSession, error) {
+	localCurvePriv, err := localSigner.CurvePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	remoteCurvePub, err := remote.CurvePublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	localAddr, err := OpenKeyP2PAddressFromSigner(localSigner)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPayloadSessionFromCurveKeys(localAddr, localCurvePriv, remote, remoteCurvePub)
+}
+
+// Erzeugt eine Sitzung aus einem bereits bekannten Curve25519 Schlüssel der Gegenseite (z.B. dem EncryptionKey aus dem Hello Paket)
+func NewPayloadSessionFromCurveKeys(localAddr *OpenKeyP2PAddress, localCurvePriv []byte, remoteAddr *OpenKeyP2PAddress, remoteCurvePub []byte) (*PayloadSession, error) {
+	sharedSecret, err := X25519SharedSecretFromCurveKeys(localCurvePriv, remoteCurvePub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Die Adressen werden sortiert, damit beide Seiten dieselben Schlüssel ableiten
+	localAddrBytes, remoteAddrBytes := localAddr.ToByteSlice(), remoteAddr.ToByteSlice()
+	localIsFirst := bytes.Compare(localAddrBytes, remoteAddrBytes) < 0
+	firstAddr, secondAddr := remoteAddrBytes, localAddrBytes
+	if localIsFirst {
+		firstAddr, secondAddr = localAddrBytes, remoteAddrBytes
+	}
+
+	session := &PayloadSession{
+		sharedSecret:  sharedSecret,
+		firstAddr:     firstAddr,
+		secondAddr:    secondAddr,
+		localIsFirst:  localIsFirst,
+		sendLock:      new(sync.Mutex),
+		recvLock:      new(sync.Mutex),
+		recvSessions:  make(map[[payloadSessionIdSize]byte]*_PayloadRecvState),
+		replayRecords: make(map[[payloadSessionIdSize]byte]_PayloadReplayRecord),
+	}
+
+	// Die Sendeseite erhält eine zufällige Sitzungs ID
+	if _, err := rand.Read(session.sendSessionId[:]); err != nil {
+		return nil, err
+	}
+	session.sendAEAD, err = session._DeriveAEAD(session.sendSessionId, localIsFirst)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Leitet die AEAD für eine Sitzungs ID und Richtung ab
+func (o *PayloadSession) _DeriveAEAD(sessionId [payloadSessionIdSize]byte, fromFirst bool) (cipher.AEAD, error) {
+	direction := "second-to-first"
+	if fromFirst {
+		direction = "first-to-second"
+	}
+
+	salt := append([]byte(payloadKeyScheduleLabel), sessionId[:]...)
+	key, err := _DerivePayloadKey(o.sharedSecret, salt, o.firstAddr, o.secondAddr, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	return chacha20poly1305.New(key)
+}
+
+// HKDF-SHA256 Schlüsselableitung, beide Adressen sowie die Richtung fließen in die Info ein
+func _DerivePayloadKey(sharedSecret []byte, salt []byte, firstAddr []byte, secondAddr []byte, direction string) ([]byte, error) {
+	info := make([]byte, 0, len(firstAddr)+len(secondAddr)+len(direction))
+	info = append(info, firstAddr...)
+	info = append(info, secondAddr...)
+	info = append(info, []byte(direction)...)
+
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, salt, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func _PayloadNonce(counter uint64) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[chacha20poly1305.NonceSize-8:], counter)
+	return nonce
+}
+
+// Verschlüsselt einen Payload, additionalData wird mit Authentifiziert aber nicht verschlüsselt
+func (o *PayloadSession) Seal(plaintext []byte, additionalData []byte) ([]byte, error) {
+	o.sendLock.Lock()
+	if o.sendCounter == math.MaxUint64 {
+		o.sendLock.Unlock()
+		return nil, fmt.Errorf("payload session exhausted")
+	}
+	counter := o.sendCounter
+	o.sendCounter++
+	o.sendLock.Unlock()
+
+	// Der Header wird gebaut
+	frame := make([]byte, payloadFrameHeaderSize, payloadFrameHeaderSize+len(plaintext)+chacha20poly1305.Overhead)
+	frame[0] = PayloadFrameVersion2
+	copy(frame[1:], o.sendSessionId[:])
+	binary.BigEndian.PutUint64(frame[1+payloadSessionIdSize:], uint64(time.Now().UnixMilli()))
+	binary.BigEndian.PutUint64(frame[1+payloadSessionIdSize+8:], counter)
+
+	aad := make([]byte, 0, payloadFrameHeaderSize+len(additionalData))
+	aad = append(aad, frame...)
+	aad = append(aad, additionalData...)
+
+	return o.sendAEAD.Seal(frame, _PayloadNonce(counter), plaintext, aad), nil
+}
+
+// Entschlüsselt einen Payload Frame, wiederholte oder veraltete Frames werden abgelehnt
+func (o *PayloadSession) Open(frame []byte, additionalData []byte) ([]byte, error) {
+	if len(frame) < payloadFrameHeaderSize+chacha20poly1305.Overhead {
+		return nil, fmt.Errorf("payload frame too short")
+	}
+	if frame[0] != PayloadFrameVersion2 {
+		return nil, fmt.Errorf("unsupported payload frame version %d", frame[0])
+	}
+	var sessionId [payloadSessionIdSize]byte
+	copy(sessionId[:], frame[1:])
+	sentAt := time.UnixMilli(int64(binary.BigEndian.Uint64(frame[1+payloadSessionIdSize:])))
+	counter := binary.BigEndian.Uint64(frame[1+payloadSessionIdSize+8 : payloadFrameHeaderSize])
+
+	// Der Zeitpunkt wird erst durch die AEAD authentifiziert, ein veränderter Zeitpunkt wird dort abgelehnt
+	now := time.Now()
+	if sentAt.Before(now.Add(-payloadMaxFrameAge)) || sentAt.After(now.Add(payloadMaxFrameAge)) {
+		return nil, fmt.Errorf("payload frame stale")
+	}
+
+	aad := make([]byte, 0, payloadFrameHeaderSize+len(additionalData))
+	aad = append(aad, frame[:payloadFrameHeaderSize]...)
+	aad = append(aad, additionalData...)
+
+	o.recvLock.Lock()
+	defer o.recvLock.Unlock()
+
+	// Der Empfangsstatus der Sitzung wird abgerufen, unbekannte Sitzungen werden erst nach erfolgreicher Prüfung gespeichert.
+	// Für verdrängte Sitzungs IDs gilt jeder Zähler bis zum Replay Eintrag als bereits empfangen
+	state, isKnown := o.recvSessions[sessionId]
+	if !isKnown {
+		o._PruneReplayRecords(now)
+		record, hasRecord := o.replayRecords[sessionId]
+		if !hasRecord && len(o.recvSessionList) >= payloadMaxRecvSessions && len(o.replayRecords) >= payloadMaxReplayRecords {
+			return nil, fmt.Errorf("too many payload sessions")
+		}
+
+		aead, err := o._DeriveAEAD(sessionId, !o.localIsFirst)
+		if err != nil {
+			return nil, err
+		}
+		state = &_PayloadRecvState{aead: aead}
+		if hasRecord {
+			state.received, state.highest, state.bitmap = true, record.highest, math.MaxUint64
+		}
+	}
+
+	// Zuerst wird geprüft ob der Zähler bereits verwendet wurde
+	if !state._CheckReplayWindow(counter) {
+		return nil, fmt.Errorf("payload frame replayed or too old")
+	}
+
+	plaintext, err := state.aead.Open(nil, _PayloadNonce(counter), frame[payloadFrameHeaderSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("payload frame authentication failed")
+	}
+
+	// Erst nach erfolgreicher Authentifizierung wird der Zähler übernommen
+	state._UpdateReplayWindow(counter)
+	if !isKnown {
+		o._StoreRecvSession(sessionId, state, now)
+	}
+	return plaintext, nil
+}
+
+// Speichert eine neue Empfangssitzung, sind zu viele vorhanden wird die älteste verworfen,
+// ihr höchster Zähler bleibt als Replay Eintrag erhalten
+func (o *PayloadSession) _StoreRecvSession(sessionId [payloadSessionIdSize]byte, state *_PayloadRecvState, now time.Time) {
+	if len(o.recvSessionList) >= payloadMaxRecvSessions {
+		evictedId := o.recvSessionList[0]
+		o.replayRecords[evictedId] = _PayloadReplayRecord{highest: o.recvSessions[evictedId].highest, expiresAt: now.Add(payloadReplayRecordLifetime)}
+		delete(o.recvSessions, evictedId)
+		o.recvSessionList = o.recvSessionList[1:]
+	}
+	delete(o.replayRecords, sessionId)
+	o.recvSessions[sessionId] = state
+	o.recvSessionList = append(o.recvSessionList, sessionId)
+}
+
+// Entfernt Replay Einträge deren Frames inzwischen veraltet sind
+func (o *PayloadSession) _PruneReplayRecords(now time.Time) {
+	for sessionId, record := range o.replayRecords {
+		if now.After(record.expiresAt) {
+			delete(o.replayRecords, sessionId)
+		}
+	}
+}
+
+// Gibt die Replay Einträge aller empfangenen Sitzungs IDs zurück, sie werden benötigt wenn die Sitzung
+// verworfen und später für dieselbe Adresse neu erzeugt wird
+func (o *PayloadSession) ExportReplayState() *PayloadReplayState {
+	o.recvLock.Lock()
+	defer o.recvLock.Unlock()
+
+	now := time.Now()
+	o._PruneReplayRecords(now)
+	reval := &PayloadReplayState{records: make(map[[payloadSessionIdSize]byte]_PayloadReplayRecord, len(o.replayRecords)+len(o.recvSessions))}
+	for sessionId, record := range o.replayRecords {
+		reval.records[sessionId] = record
+	}
+	for sessionId, state := range o.recvSessions {
+		reval.records[sessionId] = _PayloadReplayRecord{highest: state.highest, expiresAt: now.Add(payloadReplayRecordLifetime)}
+	}
+	return reval
+}
+
+// Übernimmt die Replay Einträge einer verworfenen Sitzung, muss vor dem ersten Open aufgerufen werden
+func (o *PayloadSession) ImportReplayState(state *PayloadReplayState) {
+	o.recvLock.Lock()
+	defer o.recvLock.Unlock()
+	for sessionId, record := range state.records {
+		if known, found := o.replayRecords[sessionId]; !found || record.highest > known.highest {
+			o.replayRecords[sessionId] = record
+		}
+	}
+}
+
+// Gibt an ob alle Frames der Replay Einträge inzwischen veraltet sind
+func (o *PayloadReplayState) IsExpired(now time.Time) bool {
+	for _, record := range o.records {
+		if !now.After(record.expiresAt) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *_PayloadRecvState) _CheckReplayWindow(counter uint64) bool {
+	if !o.received || counter > o.highest {
+		return true
+	}
+	diff := o.highest - counter
+	if diff >= payloadReplayWindow {
+		return false
+	}
+	return o.bitmap&(1<<diff) == 0
+}
+
+func (o *_PayloadRecvState) _UpdateReplayWindow(counter uint64) {
+	if !o.received {
+		o.received = true
+		o.highest = counter
+		o.bitmap = 1
+		return
+	}
+	if counter > o.highest {
+		shift := counter - o.highest
+		if shift >= payloadReplayWindow {
+			o.bitmap = 0
+		} else {
+			o.bitmap <<= shift
+		}
+		o.bitmap |= 1
+		o.highest = counter
+		return
+	}
+	o.bitmap |= 1 << (o.highest - counter)
+}
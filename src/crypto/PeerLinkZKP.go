@@ -1,5 +1,389 @@
 package crypto
 
-func VerifyPeerLinkZKP() (bool, error) {
-	return false, nil
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"filippo.io/edwards25519"
+	"github.com/fxamacker/cbor/v2"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// PeerLinkZKP ist ein nicht-interaktiver Schnorr/Sigma Beweis über edwards25519 (Fiat-Shamir mit SHA-512).
+//
+// Für jedes bewiesene Geheimnis x_i mit öffentlichem Punkt X_i = x_i·B wählt der Beweiser eine zufällige
+// Nonce r_i und sendet R_i = r_i·B. Die gemeinsame Challenge c wird aus dem Transkript (Typ, Adressen,
+// Link Commitment, alle R_i und Kontext) gebildet, die Antworten lauten s_i = r_i + c·x_i.
+// Der Prüfer akzeptiert wenn s_i·B == R_i + c·X_i für alle i gilt. Da alle Teilbeweise dieselbe
+// Challenge verwenden, handelt es sich um einen AND Beweis, es wird kein Geheimnis offengelegt.
+//
+//	PeerLinkZKP_SameOperator: Kenntnis der privaten Schlüssel beider Adressen
+//	PeerLinkZKP_PeerLink:     Kenntnis des privaten Schlüssels der Beweiser Adresse sowie des
+//	                          Link Geheimnisses l zum Commitment L = l·B
+//
+// Der Kontext sollte vom Prüfer vorgegeben werden (z.B. eine Zufallszahl), damit Beweise nicht wiederholt werden können.
+// Adressen und Link Commitment eines Beweises stammen vom Beweiser, der Prüfer gibt daher die erwartete Aussage vor
+// (PeerLinkZKPStatement), erst wenn der Beweis dieser entspricht wird er geprüft.
+
+type PeerLinkZKPType uint8
+
+const (
+	PeerLinkZKPVersion1 uint8 = 1
+
+	PeerLinkZKP_SameOperator PeerLinkZKPType = 1
+	PeerLinkZKP_PeerLink     PeerLinkZKPType = 2
+
+	peerLinkZKPTranscriptDomain = "OpenKeyP2P-PeerLinkZKP-v1"
+	peerLinkSecretDomain        = "OpenKeyP2P-PeerLinkSecret-v1"
+)
+
+// Öffentliches Commitment L = l·B eines Link Geheimnisses, dieses kann von beiden Peers veröffentlicht werden
+type PeerLinkCommitment []byte
+
+// Stellt ein Link Geheimnis zwischen zwei Adressen dar
+type PeerLinkSecret struct {
+	secret     *edwards25519.Scalar
+	commitment PeerLinkCommitment
+	firstAddr  *OpenKeyP2PAddress
+	secondAddr *OpenKeyP2PAddress
+}
+
+// Serialisierter Beweis welcher über den Routing Layer übertragen werden kann
+type PeerLinkZKP struct {
+	Version        uint8              `cbor:"1"`
+	Type           PeerLinkZKPType    `cbor:"2"`
+	Addresses      [][]byte           `cbor:"3"`
+	LinkCommitment PeerLinkCommitment `cbor:"4,omitempty"`
+	Commitments    [][]byte           `cbor:"5"`
+	Responses      [][]byte           `cbor:"6"`
+}
+
+// Aussage welche der Prüfer aus eigener Quelle kennt und die ein Beweis belegen muss
+type PeerLinkZKPStatement struct {
+	Type PeerLinkZKPType
+
+	// Adresse des Beweisers
+	Prover *OpenKeyP2PAddress
+
+	// Bei PeerLinkZKP_SameOperator die zweite Adresse des Betreibers, bei PeerLinkZKP_PeerLink der verbundene Peer
+	Linked *OpenKeyP2PAddress
+
+	// Nur bei PeerLinkZKP_PeerLink, das veröffentlichte Commitment des Links
+	LinkCommitment PeerLinkCommitment
+}
+
+// Leitet das Link Geheimnis zweier Adressen aus ihrem X25519 Schlüsselaustausch ab, beide Seiten erhalten dasselbe Geheimnis
+func DerivePeerLinkSecret(localSigner OpenKeyP2PSigner, remote *OpenKeyP2PAddress) (*PeerLinkSecret, error) {
+	sharedSecret, err := X25519SharedSecret(localSigner, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	localAddr, err := OpenKeyP2PAddressFromSigner(localSigner)
+	if err != nil {
+		return nil, err
+	}
+
+	return PeerLinkSecretFromBytes(sharedSecret, localAddr, remote)
+}
+
+// Erzeugt ein Link Geheimnis aus einem beliebigen zwischen zwei Adressen geteilten Geheimnis
+func PeerLinkSecretFromBytes(sharedSecret []byte, local *OpenKeyP2PAddress, remote *OpenKeyP2PAddress) (*PeerLinkSecret, error) {
+	if len(sharedSecret) < 32 {
+		return nil, fmt.Errorf("%w: link secret too short", ErrInvalidPeerLinkZKP)
+	}
+
+	// Die Adressen werden sortiert, damit beide Seiten dasselbe Geheimnis ableiten
+	firstAddr, secondAddr := local, remote
+	if _ComparePeerLinkAddresses(remote, local) < 0 {
+		firstAddr, secondAddr = remote, local
+	}
+
+	secret, err := _HashToScalar([]byte(peerLinkSecretDomain), sharedSecret, firstAddr.ToByteSlice(), secondAddr.ToByteSlice())
+	if err != nil {
+		return nil, err
+	}
+
+	return &PeerLinkSecret{
+		secret:     secret,
+		commitment: PeerLinkCommitment(new(edwards25519.Point).ScalarBaseMult(secret).Bytes()),
+		firstAddr:  firstAddr,
+		secondAddr: secondAddr,
+	}, nil
+}
+
+// Gibt das öffentliche Commitment des Link Geheimnisses zurück
+func (o *PeerLinkSecret) Commitment() PeerLinkCommitment {
+	return o.commitment
+}
+
+// Beweist dass beide Adressen vom selben Betreiber kontrolliert werden
+func ProveSameOperator(first Ed25519Signer, second Ed25519Signer, context []byte) (*PeerLinkZKP, error) {
+	firstSecret, firstAddr, err := _Ed25519SignerToScalar(first)
+	if err != nil {
+		return nil, err
+	}
+	secondSecret, secondAddr, err := _Ed25519SignerToScalar(second)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &PeerLinkZKP{
+		Version:   PeerLinkZKPVersion1,
+		Type:      PeerLinkZKP_SameOperator,
+		Addresses: [][]byte{firstAddr.ToByteSlice(), secondAddr.ToByteSlice()},
+	}
+	if err := proof._Prove([]*edwards25519.Scalar{firstSecret, secondSecret}, context); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Beweist dass die Adresse des Signers das Link Geheimnis zu einem Peer kennt, ohne es offenzulegen
+func ProvePeerLink(signer Ed25519Signer, link *PeerLinkSecret, context []byte) (*PeerLinkZKP, error) {
+	identitySecret, proverAddr, err := _Ed25519SignerToScalar(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Der Beweiser muss eine der beiden Adressen des Links sein
+	peerAddr := link.secondAddr
+	if link.secondAddr.Equal(proverAddr) {
+		peerAddr = link.firstAddr
+	} else if !link.firstAddr.Equal(proverAddr) {
+		return nil, fmt.Errorf("%w: signer is not part of the peer link", ErrInvalidPeerLinkZKP)
+	}
+
+	proof := &PeerLinkZKP{
+		Version:        PeerLinkZKPVersion1,
+		Type:           PeerLinkZKP_PeerLink,
+		Addresses:      [][]byte{proverAddr.ToByteSlice(), peerAddr.ToByteSlice()},
+		LinkCommitment: link.commitment,
+	}
+	if err := proof._Prove([]*edwards25519.Scalar{identitySecret, link.secret}, context); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Prüft ob ein PeerLinkZKP Beweis die erwartete Aussage für den angegebenen Kontext belegt
+func VerifyPeerLinkZKP(proof *PeerLinkZKP, expected PeerLinkZKPStatement, context []byte) (bool, error) {
+	if proof == nil {
+		return false, ErrInvalidPeerLinkZKP
+	}
+	if proof.Version != PeerLinkZKPVersion1 {
+		return false, fmt.Errorf("%w: unsupported version %d", ErrInvalidPeerLinkZKP, proof.Version)
+	}
+
+	// Der Beweis muss sich auf die erwartete Aussage beziehen, sonst könnte der Beweiser Adressen und Commitment frei wählen
+	if err := proof._MatchStatement(expected); err != nil {
+		return false, err
+	}
+
+	publicPoints, err := proof._PublicPoints()
+	if err != nil {
+		return false, err
+	}
+	if len(proof.Commitments) != len(publicPoints) || len(proof.Responses) != len(publicPoints) {
+		return false, fmt.Errorf("%w: invalid number of commitments", ErrInvalidPeerLinkZKP)
+	}
+
+	challenge, err := proof._Challenge(context)
+	if err != nil {
+		return false, err
+	}
+
+	for i, publicPoint := range publicPoints {
+		commitment, err := new(edwards25519.Point).SetBytes(proof.Commitments[i])
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrInvalidPeerLinkZKP, err)
+		}
+		response, err := new(edwards25519.Scalar).SetCanonicalBytes(proof.Responses[i])
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrInvalidPeerLinkZKP, err)
+		}
+
+		// s·B == R + c·X
+		left := new(edwards25519.Point).ScalarBaseMult(response)
+		right := new(edwards25519.Point).ScalarMult(challenge, publicPoint)
+		right.Add(right, commitment)
+		if left.Equal(right) != 1 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (o *PeerLinkZKP) _MatchStatement(expected PeerLinkZKPStatement) error {
+	if expected.Prover == nil || expected.Linked == nil {
+		return fmt.Errorf("%w: incomplete statement", ErrInvalidPeerLinkZKP)
+	}
+	if o.Type != expected.Type {
+		return fmt.Errorf("%w: proof type", ErrPeerLinkZKPStatementMismatch)
+	}
+	if len(o.Addresses) != 2 || !bytes.Equal(o.Addresses[0], expected.Prover.ToByteSlice()) || !bytes.Equal(o.Addresses[1], expected.Linked.ToByteSlice()) {
+		return fmt.Errorf("%w: addresses", ErrPeerLinkZKPStatementMismatch)
+	}
+	if expected.Type == PeerLinkZKP_PeerLink && (len(expected.LinkCommitment) == 0 || !bytes.Equal(o.LinkCommitment, expected.LinkCommitment)) {
+		return fmt.Errorf("%w: link commitment", ErrPeerLinkZKPStatementMismatch)
+	}
+	return nil
+}
+
+// Gibt die Adresse des Beweisers zurück
+func (o *PeerLinkZKP) GetProverAddress() (*OpenKeyP2PAddress, error) {
+	if len(o.Addresses) != 2 {
+		return nil, ErrInvalidPeerLinkZKP
+	}
+	return OpenKeyP2PAddressDecodeFromByteSlice(o.Addresses[0])
+}
+
+// Gibt die zweite Adresse zurück, je nach Typ die zweite Adresse des Betreibers oder den verbundenen Peer
+func (o *PeerLinkZKP) GetLinkedAddress() (*OpenKeyP2PAddress, error) {
+	if len(o.Addresses) != 2 {
+		return nil, ErrInvalidPeerLinkZKP
+	}
+	return OpenKeyP2PAddressDecodeFromByteSlice(o.Addresses[1])
+}
+
+// Wandelt den Beweis in Bytes um
+func (o *PeerLinkZKP) ToByteSlice() ([]byte, error) {
+	return canonicalEncMode.Marshal(o)
+}
+
+// Liest einen Beweis aus Bytes ein
+func PeerLinkZKPFromByteSlice(data []byte) (*PeerLinkZKP, error) {
+	proof := new(PeerLinkZKP)
+	if err := cbor.Unmarshal(data, proof); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPeerLinkZKP, err)
+	}
+	return proof, nil
+}
+
+// Erzeugt die Commitments und Antworten für die angegebenen Geheimnisse
+func (o *PeerLinkZKP) _Prove(secrets []*edwards25519.Scalar, context []byte) error {
+	nonces := make([]*edwards25519.Scalar, len(secrets))
+	o.Commitments = make([][]byte, len(secrets))
+	for i := range secrets {
+		nonce, err := _RandomScalar()
+		if err != nil {
+			return err
+		}
+		nonces[i] = nonce
+		o.Commitments[i] = new(edwards25519.Point).ScalarBaseMult(nonce).Bytes()
+	}
+
+	challenge, err := o._Challenge(context)
+	if err != nil {
+		return err
+	}
+
+	// s = r + c·x
+	o.Responses = make([][]byte, len(secrets))
+	for i, secret := range secrets {
+		o.Responses[i] = new(edwards25519.Scalar).MultiplyAdd(challenge, secret, nonces[i]).Bytes()
+	}
+	return nil
+}
+
+// Gibt die öffentlichen Punkte zurück deren Geheimnisse bewiesen werden
+func (o *PeerLinkZKP) _PublicPoints() ([]*edwards25519.Point, error) {
+	if len(o.Addresses) != 2 {
+		return nil, fmt.Errorf("%w: invalid number of addresses", ErrInvalidPeerLinkZKP)
+	}
+
+	addressPoints := make([]*edwards25519.Point, len(o.Addresses))
+	for i, rawAddr := range o.Addresses {
+		addr, err := OpenKeyP2PAddressDecodeFromByteSlice(rawAddr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPeerLinkZKP, err)
+		}
+		if addr.KeyType != openkeyp2p.Type_Ed25519 {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedZKPKeyType, KeyTypeName(addr.KeyType))
+		}
+		addressPoints[i], err = new(edwards25519.Point).SetBytes(addr.PubKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPeerLinkZKP, err)
+		}
+	}
+
+	switch o.Type {
+	case PeerLinkZKP_SameOperator:
+		if len(o.LinkCommitment) != 0 {
+			return nil, fmt.Errorf("%w: unexpected link commitment", ErrInvalidPeerLinkZKP)
+		}
+		return addressPoints, nil
+	case PeerLinkZKP_PeerLink:
+		linkPoint, err := new(edwards25519.Point).SetBytes(o.LinkCommitment)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPeerLinkZKP, err)
+		}
+		return []*edwards25519.Point{addressPoints[0], linkPoint}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown proof type %d", ErrInvalidPeerLinkZKP, o.Type)
+	}
+}
+
+// Bildet die Fiat-Shamir Challenge aus dem Transkript, alle Felder werden mit Längenangabe eingetragen
+func (o *PeerLinkZKP) _Challenge(context []byte) (*edwards25519.Scalar, error) {
+	transcript := []byte(peerLinkZKPTranscriptDomain)
+	transcript = append(transcript, o.Version, byte(o.Type))
+	appendField := func(field []byte) {
+		transcript = binary.BigEndian.AppendUint32(transcript, uint32(len(field)))
+		transcript = append(transcript, field...)
+	}
+	for _, addr := range o.Addresses {
+		appendField(addr)
+	}
+	appendField(o.LinkCommitment)
+	for _, commitment := range o.Commitments {
+		appendField(commitment)
+	}
+	appendField(context)
+
+	return _HashToScalar(transcript)
+}
+
+// Gibt den ED25519 Skalar (RFC 8032) sowie die Adresse eines Signers zurück
+func _Ed25519SignerToScalar(signer Ed25519Signer) (*edwards25519.Scalar, *OpenKeyP2PAddress, error) {
+	if len(signer) != ed25519.PrivateKeySize {
+		return nil, nil, fmt.Errorf("invalid private key")
+	}
+
+	digest := sha512.Sum512(ed25519.PrivateKey(signer).Seed())
+	secret, err := new(edwards25519.Scalar).SetBytesWithClamping(digest[:32])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addr, err := OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return secret, addr, nil
+}
+
+func _HashToScalar(data ...[]byte) (*edwards25519.Scalar, error) {
+	hash := sha512.New()
+	for _, item := range data {
+		hash.Write(item)
+	}
+	return new(edwards25519.Scalar).SetUniformBytes(hash.Sum(nil))
+}
+
+func _RandomScalar() (*edwards25519.Scalar, error) {
+	randomBytes := make([]byte, 64)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, err
+	}
+	return new(edwards25519.Scalar).SetUniformBytes(randomBytes)
+}
+
+func _ComparePeerLinkAddresses(first *OpenKeyP2PAddress, second *OpenKeyP2PAddress) int {
+	return bytes.Compare(first.ToByteSlice(), second.ToByteSlice())
 }
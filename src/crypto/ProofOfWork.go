@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Hashbasierter Arbeitsnachweis, gesucht wird eine Lösung für welche gilt:
+//
+//	SHA-256("OpenKeyP2P-POW-v1" || input || ser64(solution)) beginnt mit difficulty Null Bits
+//
+// Der Input muss vom Prüfer vorgegeben werden (z.B. Nonce und Schlüssel des Lösenden), damit Lösungen nicht
+// wiederverwendet werden können.
+
+const (
+	// Größte unterstützte Schwierigkeit, entspricht im Mittel 2^MaxPOWDifficulty Hashes
+	MaxPOWDifficulty uint8 = 32
+
+	powDomain = "OpenKeyP2P-POW-v1"
+
+	// Anzahl der Versuche zwischen zwei Prüfungen des Kontextes
+	powContextCheckInterval = 1 << 14
+)
+
+// Sucht eine Lösung für den Input mit der angegebenen Schwierigkeit, der Vorgang kann über den Kontext abgebrochen werden
+func SolveProofOfWork(ctx context.Context, input []byte, difficulty uint8) (uint64, error) {
+	if difficulty > MaxPOWDifficulty {
+		return 0, ErrPOWDifficultyTooHigh
+	}
+
+	// Die Suche beginnt an einer zufälligen Stelle
+	var startBytes [8]byte
+	if _, err := rand.Read(startBytes[:]); err != nil {
+		return 0, err
+	}
+	solution := binary.LittleEndian.Uint64(startBytes[:])
+
+	buffer := _POWBuffer(input)
+	for i := 0; ; i++ {
+		if i%powContextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+		if _POWLeadingZeroBits(buffer, solution) >= int(difficulty) {
+			return solution, nil
+		}
+		solution++
+	}
+}
+
+// Prüft ob die Lösung für den Input mindestens die angegebene Schwierigkeit erfüllt
+func VerifyProofOfWork(input []byte, difficulty uint8, solution uint64) bool {
+	if difficulty > MaxPOWDifficulty {
+		return false
+	}
+	return _POWLeadingZeroBits(_POWBuffer(input), solution) >= int(difficulty)
+}
+
+// Erzeugt den Puffer aus Domain, Input und Platz für die Lösung
+func _POWBuffer(input []byte) []byte {
+	buffer := make([]byte, 0, len(powDomain)+len(input)+8)
+	buffer = append(buffer, powDomain...)
+	buffer = append(buffer, input...)
+	return append(buffer, make([]byte, 8)...)
+}
+
+func _POWLeadingZeroBits(buffer []byte, solution uint64) int {
+	binary.LittleEndian.PutUint64(buffer[len(buffer)-8:], solution)
+	digest := sha256.Sum256(buffer)
+
+	reval := 0
+	for _, value := range digest {
+		if value != 0 {
+			return reval + bits.LeadingZeros8(value)
+		}
+		reval += 8
+	}
+	return reval
+}
@@ -0,0 +1,188 @@
+package crypto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// Widerruf einer Adresse. Ein Widerruf wird entweder vom widerrufenen Schlüssel selbst oder von einem
+// lokal konfigurierten Authority Schlüssel per AddressSign signiert. Widerrufene Adressen werden in einem
+// RevocationStore gespeichert, jede Signaturprüfung per VerifySignatureWithStore schlägt für die Adressen im
+// angegebenen Store fehl. Die Funktionen ohne Store (VerifySignature, AddRevocation, IsAddressRevoked, ...)
+// verwenden den Standard Store.
+
+// Domain Tag für Widerrufe
+const revocationDomain = "OpenKeyP2P-Revocation-v1"
+
+type RevocationReason uint8
+
+const (
+	RevocationReasonUnspecified   RevocationReason = 0
+	RevocationReasonKeyCompromise RevocationReason = 1
+	RevocationReasonSuperseded    RevocationReason = 2
+	RevocationReasonRetired       RevocationReason = 3
+)
+
+type RevocationStatement struct {
+	RevokedAddress []byte           `cbor:"1"`
+	RevokerAddress []byte           `cbor:"2"`
+	Reason         RevocationReason `cbor:"3"`
+	IssuedAt       int64            `cbor:"4"`
+	Signature      []byte           `cbor:"5"`
+}
+
+// Signierter Teil des Widerrufs
+type _RevocationStatementWSig struct {
+	Domain         string           `cbor:"1"`
+	RevokedAddress []byte           `cbor:"2"`
+	RevokerAddress []byte           `cbor:"3"`
+	Reason         RevocationReason `cbor:"4"`
+	IssuedAt       int64            `cbor:"5"`
+}
+
+// Erzeugt einen Widerruf der eigenen Adresse
+func NewSelfRevocation(signer OpenKeyP2PSigner, reason RevocationReason) (*RevocationStatement, error) {
+	addr, err := OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		return nil, err
+	}
+	return _NewRevocationStatement(signer, addr, reason)
+}
+
+// Erzeugt einen Widerruf einer fremden Adresse durch einen Authority Schlüssel
+func NewAuthorityRevocation(authority OpenKeyP2PSigner, revoked *OpenKeyP2PAddress, reason RevocationReason) (*RevocationStatement, error) {
+	return _NewRevocationStatement(authority, revoked, reason)
+}
+
+func _NewRevocationStatement(signer OpenKeyP2PSigner, revoked *OpenKeyP2PAddress, reason RevocationReason) (*RevocationStatement, error) {
+	revokerAddr, err := OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &RevocationStatement{
+		RevokedAddress: revoked.ToByteSlice(),
+		RevokerAddress: revokerAddr.ToByteSlice(),
+		Reason:         reason,
+		IssuedAt:       time.Now().Unix(),
+	}
+
+	dataHash, err := statement._ComputeSigningHash()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := AddressSign(signer, dataHash)
+	if err != nil {
+		return nil, err
+	}
+	statement.Signature = signature.GetRawSignature()
+
+	return statement, nil
+}
+
+// Prüft die Signatur sowie ob der Unterzeichner die Adresse laut Standard Store widerrufen darf
+func (o *RevocationStatement) Verify() error {
+	return o._Verify(defaultRevocationStore)
+}
+
+func (o *RevocationStatement) _Verify(store *RevocationStore) error {
+	revokedAddr, err := o.GetRevokedAddress()
+	if err != nil {
+		return err
+	}
+	revokerAddr, err := o.GetRevokerAddress()
+	if err != nil {
+		return err
+	}
+
+	isSelfRevocation := revokedAddr.Equal(revokerAddr)
+	if !isSelfRevocation && !store.IsRevocationAuthority(revokerAddr) {
+		return fmt.Errorf("%w: %s is no revocation authority", ErrInvalidRevocation, revokerAddr.ToString())
+	}
+	if !isSelfRevocation && store.IsAddressRevoked(revokerAddr) {
+		return fmt.Errorf("%w: %w: %s", ErrInvalidRevocation, ErrAddressRevoked, revokerAddr.ToString())
+	}
+
+	dataHash, err := o._ComputeSigningHash()
+	if err != nil {
+		return err
+	}
+
+	// Ein Selbstwiderruf muss auch dann prüfbar sein, wenn die Adresse bereits widerrufen wurde,
+	// ein widerrufener Authority Schlüssel wurde bereits oben abgelehnt
+	isValid, err := revokerAddr._VerifySignature(OpenKeyP2PSignature(o.Signature), dataHash)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRevocation, err)
+	}
+	if !isValid {
+		return fmt.Errorf("%w: invalid signature of %s", ErrInvalidRevocation, revokerAddr.ToString())
+	}
+
+	return nil
+}
+
+// Gibt die widerrufene Adresse zurück
+func (o *RevocationStatement) GetRevokedAddress() (*OpenKeyP2PAddress, error) {
+	addr, err := OpenKeyP2PAddressDecodeFromByteSlice(o.RevokedAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRevocation, err)
+	}
+	return addr, nil
+}
+
+// Gibt die Adresse des Unterzeichners zurück
+func (o *RevocationStatement) GetRevokerAddress() (*OpenKeyP2PAddress, error) {
+	addr, err := OpenKeyP2PAddressDecodeFromByteSlice(o.RevokerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRevocation, err)
+	}
+	return addr, nil
+}
+
+// Gibt den Ausstellungszeitpunkt zurück
+func (o *RevocationStatement) GetIssuedAt() time.Time {
+	return time.Unix(o.IssuedAt, 0)
+}
+
+// Wandelt den Widerruf in Bytes um
+func (o *RevocationStatement) ToByteSlice() ([]byte, error) {
+	return canonicalEncMode.Marshal(o)
+}
+
+// Liest einen Widerruf aus Bytes ein und prüft ihn gegen den Standard Store
+func RevocationStatementFromByteSlice(data []byte) (*RevocationStatement, error) {
+	statement, err := DecodeRevocationStatement(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := statement.Verify(); err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+// Liest einen Widerruf aus Bytes ein ohne ihn zu prüfen, RevocationStore.AddRevocation prüft ihn beim Speichern
+func DecodeRevocationStatement(data []byte) (*RevocationStatement, error) {
+	statement := new(RevocationStatement)
+	if err := cbor.Unmarshal(data, statement); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRevocation, err)
+	}
+	return statement, nil
+}
+
+func (o *RevocationStatement) _ComputeSigningHash() (openkeyp2p.HashSlice, error) {
+	unsignedStatement, err := canonicalEncMode.Marshal(&_RevocationStatementWSig{
+		Domain:         revocationDomain,
+		RevokedAddress: o.RevokedAddress,
+		RevokerAddress: o.RevokerAddress,
+		Reason:         o.Reason,
+		IssuedAt:       o.IssuedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, unsignedStatement)
+}
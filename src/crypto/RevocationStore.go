@@ -0,0 +1,242 @@
+package crypto
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Speicher für Widerrufe und Widerrufs-Authorities. Jeder Node kann einen eigenen Store verwenden, damit
+// mehrere unabhängige Nodes in einem Prozess laufen können. Die Liste ist auf maxRevocations Einträge
+// begrenzt, wurde mit LoadFile eine Datei festgelegt wird sie nach jedem neuen Widerruf in diese geschrieben.
+
+// Maximale Anzahl gespeicherter Widerrufe, gespeicherte Widerrufe werden nie verdrängt
+const maxRevocations = 4096
+
+type RevocationStore struct {
+	lock        *sync.Mutex
+	revoked     map[string]*RevocationStatement
+	authorities map[string]bool
+	path        string
+}
+
+// Store welcher von den Funktionen ohne Store verwendet wird
+var defaultRevocationStore = NewRevocationStore()
+
+// Erzeugt einen leeren Store welcher nur im Speicher gehalten wird
+func NewRevocationStore() *RevocationStore {
+	return &RevocationStore{
+		lock:        new(sync.Mutex),
+		revoked:     make(map[string]*RevocationStatement),
+		authorities: make(map[string]bool),
+	}
+}
+
+// Gibt den prozessweiten Standard Store zurück
+func DefaultRevocationStore() *RevocationStore {
+	return defaultRevocationStore
+}
+
+// Erlaubt einer Adresse fremde Adressen zu widerrufen
+func (o *RevocationStore) AddRevocationAuthority(address *OpenKeyP2PAddress) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.authorities[address.ToString()] = true
+}
+
+// Entzieht einer Adresse das Recht fremde Adressen zu widerrufen, bereits gespeicherte Widerrufe bleiben erhalten
+func (o *RevocationStore) RemoveRevocationAuthority(address *OpenKeyP2PAddress) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	delete(o.authorities, address.ToString())
+}
+
+func (o *RevocationStore) IsRevocationAuthority(address *OpenKeyP2PAddress) bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.authorities[address.ToString()]
+}
+
+// Prüft und speichert einen Widerruf, added gibt an ob die Adresse bisher nicht widerrufen war.
+// Kann die Widerrufsdatei nicht geschrieben werden, bleibt der Widerruf gespeichert und added ist true
+func (o *RevocationStore) AddRevocation(statement *RevocationStatement) (added bool, err error) {
+	if err := statement._Verify(o); err != nil {
+		return false, err
+	}
+	revokedAddr, err := statement.GetRevokedAddress()
+	if err != nil {
+		return false, err
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	addrKey := revokedAddr.ToString()
+	if _, found := o.revoked[addrKey]; found {
+		return false, nil
+	}
+	if len(o.revoked) >= maxRevocations {
+		return false, fmt.Errorf("%w: %d revocations stored", ErrRevocationLimitReached, len(o.revoked))
+	}
+	o.revoked[addrKey] = statement
+
+	if err := o._SaveLocked(); err != nil {
+		return true, fmt.Errorf("revocation of %s not saved: %w", addrKey, err)
+	}
+	return true, nil
+}
+
+// Gibt an ob die Adresse widerrufen wurde
+func (o *RevocationStore) IsAddressRevoked(address *OpenKeyP2PAddress) bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	_, found := o.revoked[address.ToString()]
+	return found
+}
+
+// Gibt alle gespeicherten Widerrufe zurück, der neueste Widerruf steht am Anfang
+func (o *RevocationStore) GetRevocations() []*RevocationStatement {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o._GetRevocationsLocked()
+}
+
+func (o *RevocationStore) _GetRevocationsLocked() []*RevocationStatement {
+	reval := make([]*RevocationStatement, 0, len(o.revoked))
+	for _, statement := range o.revoked {
+		reval = append(reval, statement)
+	}
+	slices.SortFunc(reval, func(a, b *RevocationStatement) int {
+		return cmp.Compare(b.IssuedAt, a.IssuedAt)
+	})
+	return reval
+}
+
+// Gibt alle gespeicherten Widerrufe als CBOR Liste zurück, damit sie lokal gesichert werden können
+func (o *RevocationStore) ExportRevocations() ([]byte, error) {
+	return canonicalEncMode.Marshal(o.GetRevocations())
+}
+
+// Liest eine mit ExportRevocations erzeugte Liste ein und gibt die Anzahl der neu widerrufenen Adressen zurück,
+// ungültige Widerrufe führen zum Abbruch
+func (o *RevocationStore) ImportRevocations(data []byte) (int, error) {
+	statements := make([]*RevocationStatement, 0)
+	if err := cbor.Unmarshal(data, &statements); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidRevocation, err)
+	}
+
+	reval := 0
+	for _, statement := range statements {
+		if statement == nil {
+			return reval, ErrInvalidRevocation
+		}
+		added, err := o.AddRevocation(statement)
+		if err != nil {
+			return reval, err
+		}
+		if added {
+			reval++
+		}
+	}
+	return reval, nil
+}
+
+// Liest die Widerrufe aus einer mit ExportRevocations kompatiblen Datei ein und schreibt danach jeden neuen
+// Widerruf in diese Datei. Ist sie nicht vorhanden wird sie erzeugt. Widerrufe von Authority Schlüsseln
+// sind nur gültig wenn die Authority zuvor mit AddRevocationAuthority hinzugefügt wurde
+func (o *RevocationStore) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+
+	reval := 0
+	if err == nil {
+		if reval, err = o.ImportRevocations(data); err != nil {
+			return reval, err
+		}
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.path = path
+	return reval, o._SaveLocked()
+}
+
+// Schreibt die Widerrufe über eine temporäre Datei und Umbenennen, damit die Datei nie unvollständig ist
+func (o *RevocationStore) _SaveLocked() error {
+	if o.path == "" {
+		return nil
+	}
+
+	data, err := canonicalEncMode.Marshal(o._GetRevocationsLocked())
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(o.path), filepath.Base(o.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+	return os.Rename(tempFile.Name(), o.path)
+}
+
+// Erlaubt einer Adresse im Standard Store fremde Adressen zu widerrufen
+func AddRevocationAuthority(address *OpenKeyP2PAddress) {
+	defaultRevocationStore.AddRevocationAuthority(address)
+}
+
+// Entzieht einer Adresse im Standard Store das Recht fremde Adressen zu widerrufen
+func RemoveRevocationAuthority(address *OpenKeyP2PAddress) {
+	defaultRevocationStore.RemoveRevocationAuthority(address)
+}
+
+func IsRevocationAuthority(address *OpenKeyP2PAddress) bool {
+	return defaultRevocationStore.IsRevocationAuthority(address)
+}
+
+// Prüft und speichert einen Widerruf im Standard Store
+func AddRevocation(statement *RevocationStatement) (added bool, err error) {
+	return defaultRevocationStore.AddRevocation(statement)
+}
+
+// Gibt an ob die Adresse im Standard Store widerrufen wurde
+func IsAddressRevoked(address *OpenKeyP2PAddress) bool {
+	return defaultRevocationStore.IsAddressRevoked(address)
+}
+
+// Gibt alle Widerrufe des Standard Stores zurück
+func GetRevocations() []*RevocationStatement {
+	return defaultRevocationStore.GetRevocations()
+}
+
+// Gibt alle Widerrufe des Standard Stores als CBOR Liste zurück
+func ExportRevocations() ([]byte, error) {
+	return defaultRevocationStore.ExportRevocations()
+}
+
+// Liest eine mit ExportRevocations erzeugte Liste in den Standard Store ein
+func ImportRevocations(data []byte) (int, error) {
+	return defaultRevocationStore.ImportRevocations(data)
+}
+
+// Legt die Datei des Standard Stores fest, siehe RevocationStore.LoadFile
+func LoadRevocationFile(path string) (int, error) {
+	return defaultRevocationStore.LoadFile(path)
+}
@@ -0,0 +1,87 @@
+package crypto
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+const secp256k1CompressedPublicKeySize = 33
+
+// SECP256K1 Implementierung von OpenKeyP2PSigner, signiert wird per ECDSA (RFC 6979, DER kodiert)
+type Secp256k1Signer struct {
+	privKey *btcec.PrivateKey
+}
+
+// Erzeugt einen Signer aus einem vorhandenen SECP256K1 Schlüssel
+func NewSecp256k1Signer(privKey *btcec.PrivateKey) *Secp256k1Signer {
+	return &Secp256k1Signer{privKey: privKey}
+}
+
+func (o *Secp256k1Signer) KeyType() openkeyp2p.OpenKeyP2PKeyType {
+	return openkeyp2p.Type_Secp256k1
+}
+
+func (o *Secp256k1Signer) PublicKey() openkeyp2p.OpenKeyP2PPublicKey {
+	return openkeyp2p.OpenKeyP2PPublicKey(o.privKey.PubKey().SerializeCompressed())
+}
+
+func (o *Secp256k1Signer) SignDigest(digest []byte) ([]byte, error) {
+	return ecdsa.Sign(o.privKey, digest).Serialize(), nil
+}
+
+func (o *Secp256k1Signer) CurvePrivateKey() ([]byte, error) {
+	return _DeriveCurvePrivateKeyFromSecret(o.privKey.Serialize(), openkeyp2p.Type_Secp256k1)
+}
+
+// SECP256K1 Implementierung von OpenKeyP2PVerifier
+type secp256k1Verifier struct{}
+
+func (secp256k1Verifier) KeyType() openkeyp2p.OpenKeyP2PKeyType {
+	return openkeyp2p.Type_Secp256k1
+}
+
+func (secp256k1Verifier) PublicKeySize() int {
+	return secp256k1CompressedPublicKeySize
+}
+
+func (secp256k1Verifier) ValidatePublicKey(pubKey openkeyp2p.OpenKeyP2PPublicKey) error {
+	if len(pubKey) != secp256k1CompressedPublicKeySize {
+		return fmt.Errorf("invalid secp256k1 public key size")
+	}
+	if _, err := btcec.ParsePubKey(pubKey); err != nil {
+		return fmt.Errorf("invalid secp256k1 public key: %w", err)
+	}
+	return nil
+}
+
+func (secp256k1Verifier) VerifyDigest(pubKey openkeyp2p.OpenKeyP2PPublicKey, digest []byte, signature []byte) (bool, error) {
+	if digest == nil {
+		return false, fmt.Errorf("invalid hash digest")
+	}
+	parsedPubKey, err := btcec.ParsePubKey(pubKey)
+	if err != nil {
+		return false, fmt.Errorf("invalid public key: %w", err)
+	}
+	parsedSignature, err := ecdsa.ParseDERSignature(signature)
+	if err != nil {
+		return false, nil
+	}
+	return parsedSignature.Verify(digest, parsedPubKey), nil
+}
+
+func (secp256k1Verifier) SignerFromSeed(seed []byte) (OpenKeyP2PSigner, error) {
+	if len(seed) != btcec.PrivKeyBytesLen {
+		return nil, fmt.Errorf("invalid seed size %d", len(seed))
+	}
+
+	// Der Seed muss ein gültiger Skalar sein (0 < seed < n)
+	var scalar btcec.ModNScalar
+	if overflow := scalar.SetByteSlice(seed); overflow || scalar.IsZero() {
+		return nil, fmt.Errorf("seed is not a valid secp256k1 private key")
+	}
+
+	return NewSecp256k1Signer(btcec.PrivKeyFromScalar(&scalar)), nil
+}
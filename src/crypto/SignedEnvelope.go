@@ -0,0 +1,175 @@
+package crypto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// Erlaubte Abweichung der Uhrzeit zwischen zwei Nodes beim Prüfen von IssuedAt
+const SignedEnvelopeMaxClockSkew = 5 * time.Minute
+
+// Signierter Umschlag für Datensätze welche zwischen Nodes ausgetauscht werden (Peer Records, Routing Ankündigungen, ...).
+// Die Signatur wird über die kanonische CBOR Kodierung aller Felder außer der Signatur gebildet,
+// die Domain verhindert dass ein Umschlag für einen anderen Datensatztypen verwendet werden kann.
+type SignedEnvelope struct {
+	Domain        string                   `cbor:"1"`
+	Signer        []byte                   `cbor:"2"`
+	HashAlgorithm openkeyp2p.HashAlgorithm `cbor:"3"`
+	IssuedAt      int64                    `cbor:"4"`
+	ExpiresAt     int64                    `cbor:"5"`
+	Payload       []byte                   `cbor:"6"`
+	Signature     []byte                   `cbor:"7"`
+}
+
+// Signierter Teil des Umschlags
+type _SignedEnvelopeWSig struct {
+	Domain        string                   `cbor:"1"`
+	Signer        []byte                   `cbor:"2"`
+	HashAlgorithm openkeyp2p.HashAlgorithm `cbor:"3"`
+	IssuedAt      int64                    `cbor:"4"`
+	ExpiresAt     int64                    `cbor:"5"`
+	Payload       []byte                   `cbor:"6"`
+}
+
+// Signiert einen Payload für die angegebene Domain, der Umschlag ist ab jetzt für lifetime gültig
+func SealEnvelope(signer OpenKeyP2PSigner, domain string, payload []byte, lifetime time.Duration) (*SignedEnvelope, error) {
+	if domain == "" {
+		return nil, fmt.Errorf("%w: empty domain", ErrInvalidEnvelope)
+	}
+	if lifetime <= 0 {
+		return nil, fmt.Errorf("%w: invalid lifetime", ErrInvalidEnvelope)
+	}
+
+	signerAddr, err := OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	envelope := &SignedEnvelope{
+		Domain:        domain,
+		Signer:        signerAddr.ToByteSlice(),
+		HashAlgorithm: openkeyp2p.DEFAULT_HASH_METHODE_256BIT,
+		IssuedAt:      now.Unix(),
+		ExpiresAt:     now.Add(lifetime).Unix(),
+		Payload:       payload,
+	}
+
+	dataHash, err := envelope._ComputeSigningHash()
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := AddressSign(signer, dataHash)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Signature = signature.GetRawSignature()
+
+	return envelope, nil
+}
+
+// Liest einen Umschlag ein und prüft Domain, Gültigkeitszeitraum und Signatur
+func OpenEnvelope(data []byte, domain string) (*SignedEnvelope, error) {
+	envelope, err := SignedEnvelopeFromByteSlice(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := envelope.Verify(domain, time.Now()); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
+
+// Prüft Domain, Gültigkeitszeitraum und Signatur zum angegebenen Zeitpunkt gegen den Standard Store
+func (o *SignedEnvelope) Verify(domain string, now time.Time) error {
+	return o.VerifyWithStore(defaultRevocationStore, domain, now)
+}
+
+// Prüft Domain, Gültigkeitszeitraum und Signatur zum angegebenen Zeitpunkt, der Unterzeichner darf in store nicht widerrufen sein
+func (o *SignedEnvelope) VerifyWithStore(store *RevocationStore, domain string, now time.Time) error {
+	if o.Domain != domain {
+		return fmt.Errorf("%w: got %q, expected %q", ErrEnvelopeDomainMismatch, o.Domain, domain)
+	}
+	if o.ExpiresAt <= o.IssuedAt {
+		return fmt.Errorf("%w: invalid validity period", ErrInvalidEnvelope)
+	}
+	if now.Add(SignedEnvelopeMaxClockSkew).Unix() < o.IssuedAt {
+		return ErrEnvelopeNotYetValid
+	}
+	if now.Unix() >= o.ExpiresAt {
+		return ErrEnvelopeExpired
+	}
+
+	signerAddr, err := o.GetSigner()
+	if err != nil {
+		return err
+	}
+
+	dataHash, err := o._ComputeSigningHash()
+	if err != nil {
+		return err
+	}
+
+	isValid, err := signerAddr.VerifySignatureWithStore(store, OpenKeyP2PSignature(o.Signature), dataHash)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEnvelopeSignature, err)
+	}
+	if !isValid {
+		return ErrInvalidEnvelopeSignature
+	}
+
+	return nil
+}
+
+// Gibt die Adresse des Unterzeichners zurück
+func (o *SignedEnvelope) GetSigner() (*OpenKeyP2PAddress, error) {
+	signerAddr, err := OpenKeyP2PAddressDecodeFromByteSlice(o.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return signerAddr, nil
+}
+
+// Gibt den Ausstellungszeitpunkt zurück
+func (o *SignedEnvelope) GetIssuedAt() time.Time {
+	return time.Unix(o.IssuedAt, 0)
+}
+
+// Gibt den Ablaufzeitpunkt zurück
+func (o *SignedEnvelope) GetExpiresAt() time.Time {
+	return time.Unix(o.ExpiresAt, 0)
+}
+
+// Wandelt den Umschlag in Bytes um
+func (o *SignedEnvelope) ToByteSlice() ([]byte, error) {
+	return canonicalEncMode.Marshal(o)
+}
+
+// Liest einen Umschlag aus Bytes ein, die Signatur wird dabei nicht geprüft
+func SignedEnvelopeFromByteSlice(data []byte) (*SignedEnvelope, error) {
+	envelope := new(SignedEnvelope)
+	if err := cbor.Unmarshal(data, envelope); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return envelope, nil
+}
+
+// Bildet den Hash über die kanonische Kodierung aller signierten Felder
+func (o *SignedEnvelope) _ComputeSigningHash() (openkeyp2p.HashSlice, error) {
+	unsignedEnvelope, err := canonicalEncMode.Marshal(&_SignedEnvelopeWSig{
+		Domain:        o.Domain,
+		Signer:        o.Signer,
+		HashAlgorithm: o.HashAlgorithm,
+		IssuedAt:      o.IssuedAt,
+		ExpiresAt:     o.ExpiresAt,
+		Payload:       o.Payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ComputeHash(o.HashAlgorithm, unsignedEnvelope)
+}
@@ -0,0 +1,173 @@
+package crypto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+)
+
+// Domain Tag für Nachfolge Einträge
+const successionRecordDomain = "OpenKeyP2P-Succession-v1"
+
+// Nachfolge Eintrag, damit wird eine Identität durch eine neue ersetzt. Der Eintrag wird vom alten sowie vom
+// neuen Schlüssel signiert, so kann der alte Schlüssel keine fremde Identität und der neue Schlüssel keine
+// fremde Vorgänger Identität beanspruchen
+type SuccessionRecord struct {
+	OldAddress   []byte `cbor:"1"`
+	NewAddress   []byte `cbor:"2"`
+	IssuedAt     int64  `cbor:"3"`
+	OldSignature []byte `cbor:"4"`
+	NewSignature []byte `cbor:"5"`
+}
+
+// Signierter Teil des Eintrags
+type _SuccessionRecordWSig struct {
+	Domain     string `cbor:"1"`
+	OldAddress []byte `cbor:"2"`
+	NewAddress []byte `cbor:"3"`
+	IssuedAt   int64  `cbor:"4"`
+}
+
+// Erzeugt einen Nachfolge Eintrag vom alten auf den neuen Schlüssel
+func NewSuccessionRecord(oldSigner OpenKeyP2PSigner, newSigner OpenKeyP2PSigner) (*SuccessionRecord, error) {
+	oldAddr, err := OpenKeyP2PAddressFromSigner(oldSigner)
+	if err != nil {
+		return nil, err
+	}
+	newAddr, err := OpenKeyP2PAddressFromSigner(newSigner)
+	if err != nil {
+		return nil, err
+	}
+	if oldAddr.Equal(newAddr) {
+		return nil, fmt.Errorf("%w: old and new address are equal", ErrInvalidSuccessionRecord)
+	}
+
+	record := &SuccessionRecord{
+		OldAddress: oldAddr.ToByteSlice(),
+		NewAddress: newAddr.ToByteSlice(),
+		IssuedAt:   time.Now().Unix(),
+	}
+
+	dataHash, err := record._ComputeSigningHash()
+	if err != nil {
+		return nil, err
+	}
+
+	// Beide Schlüssel signieren denselben Hash
+	oldSignature, err := AddressSign(oldSigner, dataHash)
+	if err != nil {
+		return nil, err
+	}
+	newSignature, err := AddressSign(newSigner, dataHash)
+	if err != nil {
+		return nil, err
+	}
+	record.OldSignature = oldSignature.GetRawSignature()
+	record.NewSignature = newSignature.GetRawSignature()
+
+	return record, nil
+}
+
+// Prüft beide Signaturen des Eintrags, beide Adressen dürfen im Standard Store nicht widerrufen sein
+func (o *SuccessionRecord) Verify() error {
+	return o.VerifyWithStore(defaultRevocationStore)
+}
+
+// Prüft beide Signaturen des Eintrags, beide Adressen dürfen in store nicht widerrufen sein
+func (o *SuccessionRecord) VerifyWithStore(store *RevocationStore) error {
+	oldAddr, err := o.GetOldAddress()
+	if err != nil {
+		return err
+	}
+	newAddr, err := o.GetNewAddress()
+	if err != nil {
+		return err
+	}
+	if oldAddr.Equal(newAddr) {
+		return fmt.Errorf("%w: old and new address are equal", ErrInvalidSuccessionRecord)
+	}
+
+	dataHash, err := o._ComputeSigningHash()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range []struct {
+		addr      *OpenKeyP2PAddress
+		signature []byte
+	}{{oldAddr, o.OldSignature}, {newAddr, o.NewSignature}} {
+		isValid, err := item.addr.VerifySignatureWithStore(store, OpenKeyP2PSignature(item.signature), dataHash)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSuccessionRecord, err)
+		}
+		if !isValid {
+			return fmt.Errorf("%w: invalid signature of %s", ErrInvalidSuccessionRecord, item.addr.ToString())
+		}
+	}
+
+	return nil
+}
+
+// Gibt die Adresse zurück welche ersetzt wird
+func (o *SuccessionRecord) GetOldAddress() (*OpenKeyP2PAddress, error) {
+	addr, err := OpenKeyP2PAddressDecodeFromByteSlice(o.OldAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSuccessionRecord, err)
+	}
+	return addr, nil
+}
+
+// Gibt die Adresse des Nachfolgers zurück
+func (o *SuccessionRecord) GetNewAddress() (*OpenKeyP2PAddress, error) {
+	addr, err := OpenKeyP2PAddressDecodeFromByteSlice(o.NewAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSuccessionRecord, err)
+	}
+	return addr, nil
+}
+
+// Gibt den Ausstellungszeitpunkt zurück
+func (o *SuccessionRecord) GetIssuedAt() time.Time {
+	return time.Unix(o.IssuedAt, 0)
+}
+
+// Wandelt den Eintrag in Bytes um
+func (o *SuccessionRecord) ToByteSlice() ([]byte, error) {
+	return canonicalEncMode.Marshal(o)
+}
+
+// Liest einen Eintrag aus Bytes ein und prüft die Signaturen
+func SuccessionRecordFromByteSlice(data []byte) (*SuccessionRecord, error) {
+	record, err := DecodeSuccessionRecord(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := record.Verify(); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Liest einen Eintrag aus Bytes ein ohne die Signaturen zu prüfen, vor der Verwendung muss Verify aufgerufen werden
+func DecodeSuccessionRecord(data []byte) (*SuccessionRecord, error) {
+	record := new(SuccessionRecord)
+	if err := cbor.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSuccessionRecord, err)
+	}
+	return record, nil
+}
+
+func (o *SuccessionRecord) _ComputeSigningHash() (openkeyp2p.HashSlice, error) {
+	unsignedRecord, err := canonicalEncMode.Marshal(&_SuccessionRecordWSig{
+		Domain:     successionRecordDomain,
+		OldAddress: o.OldAddress,
+		NewAddress: o.NewAddress,
+		IssuedAt:   o.IssuedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, unsignedRecord)
+}
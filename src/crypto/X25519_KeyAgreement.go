@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Führt einen X25519 Schlüsselaustausch zwischen dem Lokalen Schlüssel und einer OpenKeyP2P Adresse durch,
+// der öffentliche Curve25519 Schlüssel wird dabei aus dem ED25519 Schlüssel der Adresse abgeleitet
+func X25519SharedSecret(localSigner OpenKeyP2PSigner, remote *OpenKeyP2PAddress) ([]byte, error) {
+	localCurvePriv, err := localSigner.CurvePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	remoteCurvePub, err := remote.CurvePublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return X25519SharedSecretFromCurveKeys(localCurvePriv, remoteCurvePub)
+}
+
+// Führt einen X25519 Schlüsselaustausch mit bereits konvertierten Curve25519 Schlüsseln durch
+func X25519SharedSecretFromCurveKeys(localCurvePriv []byte, remoteCurvePub []byte) ([]byte, error) {
+	if len(localCurvePriv) != curve25519.ScalarSize || len(remoteCurvePub) != curve25519.PointSize {
+		return nil, fmt.Errorf("invalid curve25519 key size")
+	}
+
+	// X25519 lehnt Punkte mit kleiner Ordnung ab (Ergebnis wäre 0)
+	sharedSecret, err := curve25519.X25519(localCurvePriv, remoteCurvePub)
+	if err != nil {
+		return nil, err
+	}
+
+	return sharedSecret, nil
+}
+
+// Leitet für Schlüsseltypen ohne Curve25519 Abbildung einen privaten X25519 Schlüssel aus dem privaten Schlüssel ab
+func _DeriveCurvePrivateKeyFromSecret(secret []byte, keyType openkeyp2p.OpenKeyP2PKeyType) ([]byte, error) {
+	curvePriv := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, []byte("OpenKeyP2P-X25519-v1"), []byte(KeyTypeName(keyType))), curvePriv); err != nil {
+		return nil, err
+	}
+
+	// Clamping nach RFC 7748
+	curvePriv[0] &= 248
+	curvePriv[31] &= 127
+	curvePriv[31] |= 64
+	return curvePriv, nil
+}
@@ -0,0 +1,40 @@
+package crypto
+
+import "errors"
+
+var (
+	ErrInvalidPeerCertificate       = errors.New("invalid peer certificate")
+	ErrPeerIdentityMismatch         = errors.New("peer identity does not match the expected address")
+	ErrInvalidPeerLinkZKP           = errors.New("invalid peer link proof")
+	ErrUnsupportedZKPKeyType        = errors.New("key type is not supported by peer link proofs")
+	ErrPeerLinkZKPStatementMismatch = errors.New("peer link proof does not match the expected statement")
+	ErrInvalidSuccessionRecord      = errors.New("invalid succession record")
+	ErrInvalidDerivationPath        = errors.New("invalid derivation path")
+	ErrInvalidRevocation            = errors.New("invalid revocation statement")
+	ErrAddressRevoked               = errors.New("address has been revoked")
+	ErrRevocationLimitReached       = errors.New("revocation limit reached")
+
+	// Fehler beim Einlesen von Adressen
+	ErrInvalidAddressPrefix      = errors.New("address has no valid prefix")
+	ErrInvalidAddressLength      = errors.New("invalid address length")
+	ErrInvalidAddressEncoding    = errors.New("invalid address encoding")
+	ErrInvalidAddressChecksum    = errors.New("address checksum invalid")
+	ErrInvalidAddressKey         = errors.New("address contains an invalid public key")
+	ErrUnknownAddressKeyType     = errors.New("unknown address key type")
+	ErrUnsupportedAddressVersion = errors.New("unsupported address version")
+
+	// Fehler beim Prüfen von signierten Umschlägen
+	ErrInvalidEnvelope          = errors.New("invalid signed envelope")
+	ErrInvalidEnvelopeSignature = errors.New("invalid signed envelope signature")
+	ErrEnvelopeDomainMismatch   = errors.New("signed envelope has the wrong domain")
+	ErrEnvelopeExpired          = errors.New("signed envelope expired")
+	ErrEnvelopeNotYetValid      = errors.New("signed envelope is not yet valid")
+
+	// Fehler beim Einlesen einer Mnemonic
+	ErrInvalidMnemonicLength   = errors.New("invalid mnemonic length")
+	ErrInvalidMnemonicWord     = errors.New("unknown mnemonic word")
+	ErrInvalidMnemonicChecksum = errors.New("mnemonic checksum invalid")
+
+	// Fehler beim Arbeitsnachweis
+	ErrPOWDifficultyTooHigh = errors.New("proof of work difficulty too high")
+)
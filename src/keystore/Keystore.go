@@ -0,0 +1,183 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Aufbau einer Keystore Datei (Version 1):
+//
+//	magic   [7]byte  "OKP2PKS"
+//	version uint8    KeystoreVersion1
+//	keytype uint8    openkeyp2p.OpenKeyP2PKeyType
+//	kdf     uint8    KDF_Argon2id
+//	time    uint32   Argon2 Iterationen (BE)
+//	memory  uint32   Argon2 Speicher in KiB (BE)
+//	threads uint8    Argon2 Threads
+//	salt    [16]byte
+//	nonce   [24]byte XChaCha20-Poly1305 Nonce
+//	cipher  []byte   verschlüsselter Seed inklusive Poly1305 Tag
+//
+// Der gesamte Header (alles vor dem Ciphertext) wird als Additional Data authentifiziert.
+
+type KeystoreVersion uint8
+type KeystoreKDF uint8
+
+const (
+	KeystoreVersion1 KeystoreVersion = 1
+
+	KDF_Argon2id KeystoreKDF = 1
+
+	DefaultArgon2Time    uint32 = 3
+	DefaultArgon2Memory  uint32 = 64 * 1024
+	DefaultArgon2Threads uint8  = 4
+
+	// Obergrenzen für die Argon2 Parameter aus dem Header, eine manipulierte Datei darf beim Laden
+	// weder den gesamten Speicher belegen noch den Node blockieren
+	maxArgon2Time    uint32 = 16 * DefaultArgon2Time
+	maxArgon2Memory  uint32 = 4 * DefaultArgon2Memory
+	maxArgon2Threads uint8  = 4 * DefaultArgon2Threads
+
+	keystoreSaltSize   = 16
+	keystoreSeedSize   = 32
+	keystoreHeaderSize = 7 + 1 + 1 + 1 + 4 + 4 + 1 + keystoreSaltSize + chacha20poly1305.NonceSizeX
+)
+
+var keystoreMagic = []byte("OKP2PKS")
+
+// Entschlüsselter Inhalt einer Keystore Datei
+type KeystoreSeed struct {
+	KeyType openkeyp2p.OpenKeyP2PKeyType
+	Seed    []byte
+}
+
+// Leitet den privaten Schlüssel aus dem Seed ab
+func (o *KeystoreSeed) Signer() (crypto.OpenKeyP2PSigner, error) {
+	return crypto.NewSignerFromSeed(o.KeyType, o.Seed)
+}
+
+// Gibt die 24 Wörter zur Sicherung des Seeds zurück, siehe crypto.SeedToMnemonic
+func (o *KeystoreSeed) Mnemonic() (string, error) {
+	return crypto.SeedToMnemonic(o.Seed)
+}
+
+// Leitet den Schlüssel für die AEAD aus der Passphrase ab
+func _DeriveKeystoreKey(kdf KeystoreKDF, passphrase []byte, salt []byte, time uint32, memory uint32, threads uint8) ([]byte, error) {
+	switch kdf {
+	case KDF_Argon2id:
+		if time == 0 || memory == 0 || threads == 0 {
+			return nil, ErrInvalidKeystoreFile
+		}
+		if time > maxArgon2Time || memory > maxArgon2Memory || threads > maxArgon2Threads {
+			return nil, ErrInvalidKeystoreFile
+		}
+		return argon2.IDKey(passphrase, salt, time, memory, threads, chacha20poly1305.KeySize), nil
+	default:
+		return nil, ErrUnsupportedKeystoreKDF
+	}
+}
+
+// Verschlüsselt einen Seed mit der Passphrase und gibt die Keystore Datei als Bytes zurück
+func EncodeSeed(keyType openkeyp2p.OpenKeyP2PKeyType, seed []byte, passphrase []byte) ([]byte, error) {
+	if len(seed) != keystoreSeedSize {
+		return nil, fmt.Errorf("invalid seed size %d", len(seed))
+	}
+
+	// Es wird geprüft ob sich aus dem Seed ein gültiger Schlüssel ableiten lässt
+	if _, err := crypto.NewSignerFromSeed(keyType, seed); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedKeystoreKeyType, err)
+	}
+
+	// Es werden ein Zufälliger Salt sowie eine Zufällige Nonce erzeugt
+	salt := make([]byte, keystoreSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// Der Header wird gebaut
+	header := make([]byte, 0, keystoreHeaderSize)
+	header = append(header, keystoreMagic...)
+	header = append(header, byte(KeystoreVersion1), byte(keyType), byte(KDF_Argon2id))
+	header = binary.BigEndian.AppendUint32(header, DefaultArgon2Time)
+	header = binary.BigEndian.AppendUint32(header, DefaultArgon2Memory)
+	header = append(header, DefaultArgon2Threads)
+	header = append(header, salt...)
+	header = append(header, nonce...)
+
+	// Der Schlüssel wird abgeleitet
+	key, err := _DeriveKeystoreKey(KDF_Argon2id, passphrase, salt, DefaultArgon2Time, DefaultArgon2Memory, DefaultArgon2Threads)
+	if err != nil {
+		return nil, err
+	}
+
+	// Der Seed wird verschlüsselt, der Header wird mit Authentifiziert
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(header, nonce, seed, header), nil
+}
+
+// Entschlüsselt eine Keystore Datei mit der Passphrase
+func DecodeSeed(data []byte, passphrase []byte) (*KeystoreSeed, error) {
+	if len(data) < keystoreHeaderSize+chacha20poly1305.Overhead {
+		return nil, ErrInvalidKeystoreFile
+	}
+	if !bytes.Equal(data[:len(keystoreMagic)], keystoreMagic) {
+		return nil, ErrInvalidKeystoreFile
+	}
+
+	// Der Header wird eingelesen
+	header := data[:keystoreHeaderSize]
+	pos := len(keystoreMagic)
+	version := KeystoreVersion(header[pos])
+	keyType := openkeyp2p.OpenKeyP2PKeyType(header[pos+1])
+	kdf := KeystoreKDF(header[pos+2])
+	pos += 3
+	time := binary.BigEndian.Uint32(header[pos:])
+	memory := binary.BigEndian.Uint32(header[pos+4:])
+	threads := header[pos+8]
+	pos += 9
+	salt := header[pos : pos+keystoreSaltSize]
+	nonce := header[pos+keystoreSaltSize:]
+
+	if version != KeystoreVersion1 {
+		return nil, ErrUnsupportedKeystoreVersion
+	}
+	if _, err := crypto.GetKeyTypeVerifier(keyType); err != nil {
+		return nil, ErrUnsupportedKeystoreKeyType
+	}
+
+	// Der Schlüssel wird abgeleitet
+	key, err := _DeriveKeystoreKey(kdf, passphrase, salt, time, memory, threads)
+	if err != nil {
+		return nil, err
+	}
+
+	// Der Seed wird entschlüsselt
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := aead.Open(nil, nonce, data[keystoreHeaderSize:], header)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	if len(seed) != keystoreSeedSize {
+		return nil, ErrInvalidKeystoreFile
+	}
+
+	return &KeystoreSeed{KeyType: keyType, Seed: seed}, nil
+}
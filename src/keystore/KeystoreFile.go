@@ -0,0 +1,107 @@
+package keystore
+
+import (
+	"crypto/rand"
+	"errors"
+	"io/fs"
+	"os"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+)
+
+// Erzeugt einen neuen Zufälligen Seed und speichert ihn verschlüsselt in einer neuen Keystore Datei
+func CreateKeystoreFile(path string, keyType openkeyp2p.OpenKeyP2PKeyType, passphrase []byte) (crypto.OpenKeyP2PSigner, error) {
+	seed := make([]byte, keystoreSeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+
+	signer, err := crypto.NewSignerFromSeed(keyType, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := WriteKeystoreFile(path, keyType, seed, passphrase); err != nil {
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+// Speichert einen Seed verschlüsselt in einer neuen Keystore Datei, eine vorhandene Datei wird nicht überschrieben
+func WriteKeystoreFile(path string, keyType openkeyp2p.OpenKeyP2PKeyType, seed []byte, passphrase []byte) error {
+	encoded, err := EncodeSeed(keyType, seed, passphrase)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return ErrKeystoreFileExists
+		}
+		return err
+	}
+
+	if _, err := file.Write(encoded); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+
+	return file.Close()
+}
+
+// Stellt eine Identität aus ihrer Mnemonic wieder her und speichert sie verschlüsselt in einer neuen Keystore Datei,
+// mnemonicPassphrase ist die optionale Passphrase der Mnemonic, passphrase die der Keystore Datei
+func WriteKeystoreFileFromMnemonic(path string, keyType openkeyp2p.OpenKeyP2PKeyType, mnemonic string, mnemonicPassphrase string, passphrase []byte) (crypto.OpenKeyP2PSigner, error) {
+	seed, err := crypto.MnemonicToSeed(mnemonic, mnemonicPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := crypto.NewSignerFromSeed(keyType, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := WriteKeystoreFile(path, keyType, seed, passphrase); err != nil {
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+// Lädt den entschlüsselten Inhalt einer vorhandenen Keystore Datei, z.B. zur Sicherung als Mnemonic
+func LoadKeystoreSeedFile(path string, passphrase []byte) (*KeystoreSeed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeSeed(data, passphrase)
+}
+
+// Lädt den Privaten Schlüssel aus einer vorhandenen Keystore Datei
+func LoadKeystoreFile(path string, passphrase []byte) (crypto.OpenKeyP2PSigner, error) {
+	keystoreSeed, err := LoadKeystoreSeedFile(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return keystoreSeed.Signer()
+}
+
+// Lädt die Keystore Datei, sollte sie nicht vorhanden sein wird eine neue Identität des angegebenen Typen erzeugt
+func LoadOrCreateKeystoreFile(path string, keyType openkeyp2p.OpenKeyP2PKeyType, passphrase []byte) (crypto.OpenKeyP2PSigner, error) {
+	signer, err := LoadKeystoreFile(path, passphrase)
+	if err == nil {
+		return signer, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return CreateKeystoreFile(path, keyType, passphrase)
+}
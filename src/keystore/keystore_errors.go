@@ -0,0 +1,12 @@
+package keystore
+
+import "errors"
+
+var (
+	ErrInvalidKeystoreFile        = errors.New("invalid keystore file")
+	ErrUnsupportedKeystoreVersion = errors.New("unsupported keystore version")
+	ErrUnsupportedKeystoreKDF     = errors.New("unsupported keystore kdf")
+	ErrUnsupportedKeystoreKeyType = errors.New("unsupported keystore key type")
+	ErrInvalidPassphrase          = errors.New("invalid passphrase or corrupted keystore")
+	ErrKeystoreFileExists         = errors.New("keystore file already exists")
+)
@@ -3,35 +3,24 @@ package p2p
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
-	"net"
 	"sync"
 
 	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
 	"github.com/ms2sh/OpenKeyP2P/src/logging"
-	"github.com/quic-go/quic-go"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
 )
 
-func _HandleSession(session quic.Connection, listenerConfig *NodeP2PListenerConfig) {
-	// Context erzeugen
-	ctx, cancel := context.WithCancelCause(context.Background())
+func (o *Node) _HandleSession(session transport.Conn, listenerConfig *NodeP2PListenerConfig) {
+	// Context erzeugen, er wird beim erzwungenen Schließen des Nodes beendet
+	ctx, cancel := context.WithCancelCause(o.ctx)
 
-	// Ermittelt das passende Netzwerkinterface anhand der IP Adresse
-	ip, _, err := net.SplitHostPort(session.LocalAddr().String())
-	if err != nil {
-		err = fmt.Errorf("_HandleSession: %w", err)
-		cancel(err)
-		return
-	}
-	localhostNetworkInterface, err := getInterfaceByIP(ip)
-	if err != nil {
-		err = fmt.Errorf("_HandleSession: %w", err)
-		cancel(err)
-		return
-	}
+	// Der Verbindungsversuch fließt in die lastabhängige Schwierigkeit des Arbeitsnachweises ein
+	o._RecordIncomingPOWAttempt()
 
 	// Verbindung wird Initalisieren
-	conn, err := _InitQUICNodeConn(localhostNetworkInterface, ctx, cancel, true, listenerConfig.GetConnectionConfig(), session)
+	conn, err := o._InitNodeConn(ctx, cancel, true, listenerConfig.GetConnectionConfig(), listenerConfig.POWBaseDifficulty, session)
 	if err != nil {
 		ert := fmt.Errorf("fehler beim Initalisieren einer Verbindung: %v", err)
 		cancel(ert)
@@ -39,8 +28,9 @@ func _HandleSession(session quic.Connection, listenerConfig *NodeP2PListenerConf
 	}
 
 	// Verbindung wird Global zwischengespeichert
-	if err := _VarsAddNodeConnection(conn); err != nil {
+	if err := o._AddConnection(conn); err != nil {
 		cancel(err)
+		session.CloseWithError(ErrorCodeDisconnected, err.Error())
 		return
 	}
 
@@ -48,16 +38,23 @@ func _HandleSession(session quic.Connection, listenerConfig *NodeP2PListenerConf
 	_SyncHandleConnection(conn)
 
 	// Die Verbindung wird Global gelöscht
-	_VarsDeleteNodeConnection(conn)
+	o._DeleteConnection(conn)
 }
 
-func _StartListenerGoroutine(listeneraddr openkeyp2p.LocalListenerAddress, listener *NodeP2Listener, config *NodeP2PListenerConfig) {
+func (o *Node) _StartListenerGoroutine(listeneraddr openkeyp2p.LocalListenerAddress, listener *NodeP2Listener, config *NodeP2PListenerConfig) {
 	logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Accepts incoming connections on %s", listeneraddr)
+	o.routines.Add(1)
 	go func() {
+		defer o.routines.Done()
 		for {
-			// Neue QUIC-Verbindung akzeptieren
+			// Neue Verbindung akzeptieren
 			session, err := listener.listener.Accept(context.Background())
 			if err != nil {
+				// Der Listener wurde durch Close() geschlossen
+				if errors.Is(err, transport.ErrListenerClosed) {
+					logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Stopped accepting incoming connections on %s", listeneraddr)
+					return
+				}
 				logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by accepting connection %s", err, listeneraddr)
 				continue
 			}
@@ -69,15 +66,28 @@ func _StartListenerGoroutine(listeneraddr openkeyp2p.LocalListenerAddress, liste
 			logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Incoming connection accepted %s -> %s", remoteEndpointStr, listeneraddr)
 
 			// Falls NIST ECC genutzt wird, Verbindung weiterverarbeiten
-			go _HandleSession(session, config)
+			o.routines.Add(1)
+			go func() {
+				defer o.routines.Done()
+				o._HandleSession(session, config)
+			}()
 		}
 	}()
 }
 
+// Startet einen Listener auf dem Standard Node
 func AddListener(localIp string, localPort uint32, tlsConfig *tls.Config, config *NodeP2PListenerConfig) error {
-	// Prüft ob die Gloablen Variablen Initalisiert wurden
-	if !_VarsWasSetuped() {
-		return fmt.Errorf("you must setup p2p node functions, call Setup()")
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+	return node.AddListener(localIp, localPort, tlsConfig, config)
+}
+
+func (o *Node) AddListener(localIp string, localPort uint32, tlsConfig *tls.Config, config *NodeP2PListenerConfig) error {
+	// Prüft ob der Node noch läuft
+	if !o._IsRunning() {
+		return ErrNodeClosed
 	}
 
 	// Die Lokale IP wird geprüft
@@ -94,20 +104,22 @@ func AddListener(localIp string, localPort uint32, tlsConfig *tls.Config, config
 	// LOG
 	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "A new listener is started on %s", finalAddress)
 
-	// UDP-Listener erstellen
-	addr, err := net.ResolveUDPAddr("udp", finalAddress)
-	if err != nil {
-		return err
-	}
-	udpConn, err := net.ListenUDP("udp", addr)
+	// QUIC-Listener starten
+	listener, err := transport.ListenQuic(finalAddress, tlsConfig)
 	if err != nil {
 		return err
 	}
 
-	// QUIC-Listener starten
-	listener, err := quic.Listen(udpConn, tlsConfig, nil)
-	if err != nil {
-		return err
+	return o.AddTransportListener(listener, config)
+}
+
+// Nimmt eingehende Verbindungen über einen bereits geöffneten Listener an, z.B. aus einem transport.MemoryNetwork.
+// Der Listener gehört danach dem Node und wird von Close() geschlossen.
+func (o *Node) AddTransportListener(listener transport.Listener, config *NodeP2PListenerConfig) error {
+	// Prüft ob der Node noch läuft
+	if !o._IsRunning() {
+		listener.Close()
+		return ErrNodeClosed
 	}
 
 	// Das Rückgabe Objekt wird erstellt
@@ -117,8 +129,14 @@ func AddListener(localIp string, localPort uint32, tlsConfig *tls.Config, config
 		lock:     new(sync.Mutex),
 	}
 
+	// Der Listener wird gespeichert, damit er von Close() geschlossen werden kann
+	if err := o._AddListener(resolve); err != nil {
+		listener.Close()
+		return err
+	}
+
 	// Die Goroutine für den Listener wird gestaret
-	_StartListenerGoroutine(openkeyp2p.LocalListenerAddress(finalAddress), resolve, config)
+	o._StartListenerGoroutine(openkeyp2p.LocalListenerAddress(listener.Addr().String()), resolve, config)
 
 	// Das Objket wird zurückgegeben
 	return nil
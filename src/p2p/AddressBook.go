@@ -0,0 +1,68 @@
+package p2p
+
+import (
+	"strings"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/addressbook"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+)
+
+// Legt das Adressbuch des Standard Nodes fest, es bleibt auch nach Close() und erneutem Setup() erhalten
+func SetAddressBook(book *addressbook.AddressBook) {
+	controlLock.Lock()
+	defer controlLock.Unlock()
+	defaultAddressBook = book
+	if defaultNode != nil {
+		defaultNode.SetAddressBook(book)
+	}
+}
+
+// Gibt das Adressbuch des Standard Nodes zurück
+func GetAddressBook() *addressbook.AddressBook {
+	controlLock.Lock()
+	defer controlLock.Unlock()
+	return defaultAddressBook
+}
+
+// Legt das Adressbuch fest welches bei jedem Verbindungsaufbau geprüft wird, nil deaktiviert die Prüfung
+func (o *Node) SetAddressBook(book *addressbook.AddressBook) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.addressBook = book
+}
+
+// Gibt das aktuell verwendete Adressbuch zurück
+func (o *Node) GetAddressBook() *addressbook.AddressBook {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.addressBook
+}
+
+// Prüft die Adresse der Gegenseite gegen das Adressbuch, bei ausgehenden Verbindungen wird zusätzlich
+// geprüft ob unter dem Endpunkt bisher eine andere Adresse bekannt war
+func (o *Node) _ConsultAddressBook(peerAddress *crypto.OpenKeyP2PAddress, endpoint string, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress) error {
+	book := o.GetAddressBook()
+	if book == nil {
+		return nil
+	}
+
+	result, previous, err := book.Observe(peerAddress, endpoint)
+	if err != nil {
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Peer rejected by address book {%s} %s -> %s", err, localSocketEp, remoteSocketEp)
+		return err
+	}
+
+	switch result {
+	case addressbook.ObservationNew:
+		logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "New peer %s added to address book (tofu) %s -> %s", peerAddress.ToString(), localSocketEp, remoteSocketEp)
+	case addressbook.ObservationChanged:
+		previousStrs := make([]string, 0, len(previous))
+		for _, addr := range previous {
+			previousStrs = append(previousStrs, addr.ToString())
+		}
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "WARNING: identity behind endpoint %s changed from %s to %s %s -> %s", endpoint, strings.Join(previousStrs, ", "), peerAddress.ToString(), localSocketEp, remoteSocketEp)
+	}
+	return nil
+}
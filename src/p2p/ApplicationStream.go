@@ -0,0 +1,232 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
+)
+
+// Anwendungen können über eine Verbindung eigene Streams öffnen, ähnlich multistream-select beginnt jeder
+// Stream mit einer Protokollauswahl:
+//
+//	Öffnender  -> L1StreamProtocolSelectPacket{ProtocolId}
+//	Gegenseite -> L1StreamProtocolSelectReplyPacket{ProtocolId, Accepted}
+//
+// Beide Pakete werden wie alle Stream Pakete mit vorangestellter Länge übertragen. Besitzt die Gegenseite
+// keinen Handler für das Protokoll, antwortet sie mit Accepted=false und schließt den Stream. Danach gehört
+// der Stream vollständig der Anwendung, der Control und der Traffic Stream bleiben davon unberührt.
+
+// Maximale Länge einer Protokoll ID in Bytes
+const maxStreamProtocolIdLength = 256
+
+// Maximale Größe eines Paketes der Protokollauswahl, die Protokoll ID zuzüglich CBOR Kodierung
+const maxStreamProtocolSelectPacketSize = maxStreamProtocolIdLength + 32
+
+// Zeit welche die Gegenseite für die Protokollauswahl hat
+const streamProtocolSelectTimeout = 10 * time.Second
+
+// Bezeichnet das Protokoll eines Anwendungs Streams, z.B. "/chat/1.0.0"
+type StreamProtocolId string
+
+// Wird für jeden angenommenen Anwendungs Stream in einer eigenen Goroutine aufgerufen, source ist die geprüfte
+// Identität der Gegenseite. Close() beendet die Schreibrichtung, wird die Verbindung getrennt bricht der Stream ab
+type StreamHandler func(source *crypto.OpenKeyP2PAddress, stream io.ReadWriteCloser)
+
+// Öffnet über den Standard Node einen Anwendungs Stream zu einem direkt verbundenen Peer
+func OpenStream(peer *crypto.OpenKeyP2PAddress, protocolId StreamProtocolId) (io.ReadWriteCloser, error) {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return nil, err
+	}
+	return node.OpenStream(peer, protocolId)
+}
+
+// Legt den Handler für ein Protokoll des Standard Nodes fest
+func SetStreamHandler(protocolId StreamProtocolId, handler StreamHandler) error {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+	return node.SetStreamHandler(protocolId, handler)
+}
+
+// Öffnet einen Anwendungs Stream zu einem direkt verbundenen Peer, bestehen mehrere Verbindungen wird die älteste
+// verwendet. Der Stream wird erst zurückgegeben wenn die Gegenseite das Protokoll angenommen hat
+func (o *Node) OpenStream(peer *crypto.OpenKeyP2PAddress, protocolId StreamProtocolId) (io.ReadWriteCloser, error) {
+	if !o._IsRunning() {
+		return nil, ErrNodeClosed
+	}
+	if err := _ValidateStreamProtocolId(protocolId); err != nil {
+		return nil, err
+	}
+
+	conn, err := o._GetPeerConnection(peer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Der Stream wird geöffnet
+	ctx, cancel := context.WithTimeout(conn.ctx, streamProtocolSelectTimeout)
+	defer cancel()
+	stream, err := conn.conn.OpenStreamSync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("OpenStream: %w", err)
+	}
+
+	// Das Protokoll wird ausgewählt
+	reply, err := _SelectStreamProtocol(conn, stream, protocolId)
+	if err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("OpenStream: %w", err)
+	}
+	if !reply.Accepted || reply.ProtocolId != protocolId {
+		stream.Close()
+		return nil, fmt.Errorf("%w: %s", ErrProtocolNotSupported, protocolId)
+	}
+
+	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Application stream '%s' opened %s -> %s", protocolId, conn.localSocketAddress, conn.remoteSocketAddress)
+	return stream, nil
+}
+
+// Legt den Handler für ein Protokoll fest, nil entfernt den Handler und neue Streams mit dem Protokoll werden abgelehnt
+func (o *Node) SetStreamHandler(protocolId StreamProtocolId, handler StreamHandler) error {
+	if err := _ValidateStreamProtocolId(protocolId); err != nil {
+		return err
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if handler == nil {
+		delete(o.streamHandlers, protocolId)
+	} else {
+		o.streamHandlers[protocolId] = handler
+	}
+	return nil
+}
+
+func (o *Node) _GetStreamHandler(protocolId StreamProtocolId) StreamHandler {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.streamHandlers[protocolId]
+}
+
+func _ValidateStreamProtocolId(protocolId StreamProtocolId) error {
+	if len(protocolId) == 0 || len(protocolId) > maxStreamProtocolIdLength {
+		return fmt.Errorf("%w: length must be between 1 and %d bytes", ErrInvalidProtocolId, maxStreamProtocolIdLength)
+	}
+	if !utf8.ValidString(string(protocolId)) {
+		return fmt.Errorf("%w: not valid utf-8", ErrInvalidProtocolId)
+	}
+	return nil
+}
+
+// Sendet die Protokollauswahl und wartet auf die Antwort der Gegenseite
+func _SelectStreamProtocol(conn *NodeP2PConnection, stream transport.Stream, protocolId StreamProtocolId) (*L1StreamProtocolSelectReplyPacket, error) {
+	selectPacket, err := _SerializeSteamPacket(&L1StreamProtocolSelectPacket{ProtocolId: protocolId})
+	if err != nil {
+		return nil, err
+	}
+	if err := _StreamWriteBytePacket(stream, selectPacket, conn.localSocketAddress, conn.remoteSocketAddress, conn.contextCancel); err != nil {
+		return nil, err
+	}
+
+	// Die Antwort muss innerhalb der Wartezeit eintreffen
+	stream.SetReadDeadline(time.Now().Add(streamProtocolSelectTimeout))
+	bytedReply, err := _StreamReadLimitedBytePacket(stream, maxStreamProtocolSelectPacketSize, conn.localSocketAddress, conn.remoteSocketAddress)
+	if err != nil {
+		return nil, err
+	}
+	stream.SetReadDeadline(time.Time{})
+
+	reply, err := _DeserializeStreamProtocolSelectReplyPacket(bytedReply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// Liest die Protokollauswahl eines eingehenden Streams und übergibt ihn an den Handler
+func _HandleIncomingApplicationStream(conn *NodeP2PConnection, stream transport.Stream) {
+	// Die Protokollauswahl muss innerhalb der Wartezeit eintreffen
+	stream.SetReadDeadline(time.Now().Add(streamProtocolSelectTimeout))
+	bytedSelect, err := _StreamReadLimitedBytePacket(stream, maxStreamProtocolSelectPacketSize, conn.localSocketAddress, conn.remoteSocketAddress)
+	if err != nil {
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid application stream dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		stream.Close()
+		return
+	}
+	stream.SetReadDeadline(time.Time{})
+
+	selectPacket, err := _DeserializeStreamProtocolSelectPacket(bytedSelect)
+	if err == nil {
+		err = _ValidateStreamProtocolId(selectPacket.ProtocolId)
+	}
+	if err != nil {
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid application stream dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		stream.Close()
+		return
+	}
+
+	// Die Antwort wird gesendet, ohne Handler wird der Stream abgelehnt
+	handler := conn.node._GetStreamHandler(selectPacket.ProtocolId)
+	reply, err := _SerializeSteamPacket(&L1StreamProtocolSelectReplyPacket{ProtocolId: selectPacket.ProtocolId, Accepted: handler != nil})
+	if err != nil {
+		stream.Close()
+		return
+	}
+	if err := _StreamWriteBytePacket(stream, reply, conn.localSocketAddress, conn.remoteSocketAddress, conn.contextCancel); err != nil {
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by answering application stream '%s' {%s} %s -> %s", selectPacket.ProtocolId, err, conn.localSocketAddress, conn.remoteSocketAddress)
+		stream.Close()
+		return
+	}
+	if handler == nil {
+		logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Application stream '%s' rejected, no handler registered %s -> %s", selectPacket.ProtocolId, conn.localSocketAddress, conn.remoteSocketAddress)
+		stream.Close()
+		return
+	}
+
+	// Der Handler gehört der Anwendung, er wird daher nicht von den Routinen der Verbindung erfasst
+	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Application stream '%s' accepted %s -> %s", selectPacket.ProtocolId, conn.localSocketAddress, conn.remoteSocketAddress)
+	go handler(conn.GetRemoteAddress(), stream)
+}
+
+// Nimmt die Anwendungs Streams der Gegenseite an, jede Protokollauswahl wird in einer eigenen Goroutine verarbeitet
+func _ApplicationStreamAcceptRoutineRootFunction(conn *NodeP2PConnection, wg *sync.WaitGroup) {
+	// Wird ausgeführt wenn die Funktion am ende ist
+	defer func() {
+		// LOG
+		logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Application stream accept routine stopped %s -> %s", conn.localSocketAddress, conn.remoteSocketAddress)
+	}()
+
+	// Es wird Signalisiert das die Routine vollständig ausgeführt wird
+	wg.Done()
+
+	for {
+		stream, err := conn.conn.AcceptStream(conn.ctx)
+		if err != nil {
+			// Wurde der Kontext geschlossen, wird die Routine ohne Fehler beendet
+			if conn.ctx.Err() != nil {
+				return
+			}
+
+			// Der Fehler wird an den Context übergeben
+			conn.contextCancel(fmt.Errorf("_ApplicationStreamAcceptRoutineRootFunction: %s", err))
+			return
+		}
+
+		// Die Routine ist selbst erfasst, daher darf die Waitgroup hier erhöht werden
+		conn.routines.Add(1)
+		go func() {
+			defer conn.routines.Done()
+			_HandleIncomingApplicationStream(conn, stream)
+		}()
+	}
+}
@@ -1,5 +1,88 @@
 package p2p
 
-func Close() {
+import (
+	"context"
+	"fmt"
 
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+)
+
+// Schließt den Node geordnet: Es werden keine neuen Verbindungen mehr angenommen, jede Verbindung erhält ein
+// Goodbye Paket und der Schreibpuffer wird geleert. Danach wird gewartet bis alle Routinen beendet wurden.
+// Läuft ctx vorher ab, werden die verbleibenden Verbindungen hart getrennt. Ein geschlossener Node kann
+// nicht erneut gestartet werden.
+func (o *Node) Close(ctx context.Context) error {
+	o.lock.Lock()
+	if o.isClosing || o.isClosed {
+		o.lock.Unlock()
+		return ErrNodeClosed
+	}
+	o.isClosing = true
+	listeners := o.listeners
+	o.lock.Unlock()
+
+	logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Closing p2p node")
+
+	// Es werden keine neuen Verbindungen mehr angenommen, die Hintergrundroutinen werden beendet
+	o.shutdown()
+	for _, listener := range listeners {
+		listener.lock.Lock()
+		if err := listener.listener.Close(); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by closing listener {%s}", err)
+		}
+		listener.lock.Unlock()
+	}
+
+	// Jede Verbindung wird verabschiedet
+	for _, conn := range o._GetConnections() {
+		if err := _SayGoodbye(conn, GoodbyeReasonShutdown); err != nil {
+			logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Goodbye could not be queued {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
+	}
+
+	// Es wird gewartet bis alle Routinen beendet wurden
+	done := make(chan struct{})
+	go func() {
+		o.routines.Wait()
+		close(done)
+	}()
+
+	var reval error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Die Frist ist abgelaufen, alle verbleibenden Verbindungen und Handshakes werden hart getrennt
+		reval = fmt.Errorf("%w: %w", ErrNodeClosed, ctx.Err())
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Deadline exceeded by closing p2p node, remaining connections are terminated")
+	}
+	o.cancel(ErrNodeClosed)
+
+	o.lock.Lock()
+	o.isClosing = false
+	o.isClosed = true
+	o.listeners = nil
+	o.lock.Unlock()
+
+	if reval == nil {
+		logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "P2p node closed")
+	}
+	return reval
+}
+
+// Schließt den Standard Node, anschließend kann Setup() erneut aufgerufen werden
+func Close(ctx context.Context) error {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+
+	err = node.Close(ctx)
+
+	controlLock.Lock()
+	if defaultNode == node {
+		defaultNode = nil
+	}
+	controlLock.Unlock()
+	return err
 }
@@ -7,20 +7,24 @@ import (
 	"net"
 	"net/url"
 
-	"github.com/quic-go/quic-go"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
 )
 
+// Baut über den Standard Node eine Verbindung auf
 func ConnectTo(nodeUri string, tlsConfig *tls.Config, config NodeP2PConnectionConfig) error {
-	controlUnlockByEnd := false
-	defer func() {
-		if !controlUnlockByEnd {
-			return
-		}
-		controlLock.Unlock()
-	}()
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+	return node.ConnectTo(nodeUri, tlsConfig, config)
+}
 
-	if !_VarsWasSetuped() {
-		return fmt.Errorf("you must setup p2p node functions, call Setup()")
+// Baut eine Verbindung zum Node unter nodeUri auf, ist tlsConfig nil wird eine an die Identität des Nodes
+// gebundene TLS Konfiguration erzeugt
+func (o *Node) ConnectTo(nodeUri string, tlsConfig *tls.Config, config NodeP2PConnectionConfig) error {
+	if !o._IsRunning() {
+		return ErrNodeClosed
 	}
 
 	parsedURL, err := url.Parse(nodeUri)
@@ -53,6 +57,30 @@ func ConnectTo(nodeUri string, tlsConfig *tls.Config, config NodeP2PConnectionCo
 		return fmt.Errorf("query parameters are not allowed")
 	}
 
+	// Ohne eigene TLS Konfiguration wird das Zertifikat aus der Identität des Nodes erzeugt
+	if tlsConfig == nil {
+		if tlsConfig, err = o.GenerateTLSConfig(nil); err != nil {
+			return fmt.Errorf("ConnectToNode: %w", err)
+		}
+	}
+
+	// Sofern eine Adresse angegeben wurde (quic://okp2p...@host:port), muss die Gegenseite diese Identität besitzen
+	var expectedPeerAddress *crypto.OpenKeyP2PAddress
+	if parsedURL.User != nil {
+		if _, hasPassword := parsedURL.User.Password(); hasPassword {
+			return fmt.Errorf("password in node uri is not allowed")
+		}
+		expectedPeerAddress, err = crypto.OpenKeyP2PAddressDecodeFromString(parsedURL.User.Username())
+		if err != nil {
+			return fmt.Errorf("ConnectToNode: invalid peer address: %w", err)
+		}
+
+		// Die TLS Konfiguration wird kopiert, damit die Prüfung nur für diese Verbindung gilt,
+		// hat die Gegenseite ihre Identität gewechselt wird auch der bekannte Nachfolger akzeptiert
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.VerifyPeerCertificate = o._VerifyPinnedPeerCertificate(expectedPeerAddress)
+	}
+
 	// Es wird eine Verbindung mit dem Node hergestellt
 	useAsProxy := false
 	var finalNodeAddress string
@@ -80,60 +108,61 @@ func ConnectTo(nodeUri string, tlsConfig *tls.Config, config NodeP2PConnectionCo
 		return fmt.Errorf("unkown protocol, only quic supported: %s", parsedURL.Scheme)
 	}
 
-	// Jeder Client bekommt seinen eigenen Kontext
-	ctx, cancel := context.WithCancelCause(context.Background())
-
-	// Die Quic Verbindung wird aufgebaut
-	var nodeConn *NodeP2PConnection
-	var conn quic.Connection
-	if !useAsProxy {
-		// Die Quic Verbindung wird aufgebaut
-		var err error
-		conn, err = quic.DialAddr(ctx, finalNodeAddress, tlsConfig, nil)
-		if err != nil {
-			err = fmt.Errorf("ConnectToNode: %w", err)
-			cancel(err)
-			return err
-		}
+	// Verlangt der Peer über eine bestehende Verbindung mehr Arbeit als der Node leisten will, wird nicht verbunden
+	if err := o._CheckAnnouncedPOWDifficulty(expectedPeerAddress, NodeP2PSocketAddress(finalNodeAddress)); err != nil {
+		return fmt.Errorf("ConnectToNode: %w", err)
+	}
 
-		// Es wird das passende Interface für die Lokale IP-Adresse ermittelt
-		ip, _, err := net.SplitHostPort(conn.LocalAddr().String())
-		if err != nil {
-			err = fmt.Errorf("ConnectToNode: %w", err)
-			cancel(err)
-			return err
-		}
-		if ip == "::" || ip == "0.0.0.0" {
-			ip = getLocalIPFromConn(conn)
-		}
-		localhostNetworkInterface, err := getInterfaceByIP(ip)
-		if err != nil {
-			err = fmt.Errorf("ConnectToNode: %w", err)
-			cancel(err)
-			return err
-		}
+	// Jeder Client bekommt seinen eigenen Kontext, er wird beim erzwungenen Schließen des Nodes beendet
+	ctx, cancel := context.WithCancelCause(o.ctx)
 
-		// Die Verbindung wird Initialisiert
-		nodeConn, err = _InitQUICNodeConn(localhostNetworkInterface, ctx, cancel, false, config, conn)
-		if err != nil {
-			cancel(err)
-			return err
-		}
-	} else {
+	// Die Quic Verbindung wird aufgebaut
+	if useAsProxy {
 		cancel(nil)
 		return fmt.Errorf("not supported parameter")
 	}
+	conn, err := transport.DialQuic(ctx, finalNodeAddress, tlsConfig)
+	if err != nil {
+		err = fmt.Errorf("ConnectToNode: %w", err)
+		cancel(err)
+		return err
+	}
+
+	return o._ConnectOutgoing(ctx, cancel, conn, config)
+}
+
+// Übernimmt eine bereits aufgebaute ausgehende Verbindung, z.B. aus einem transport.MemoryNetwork,
+// und führt den Handshake durch. Die Verbindung gehört danach dem Node.
+func (o *Node) ConnectTransport(conn transport.Conn, config NodeP2PConnectionConfig) error {
+	if !o._IsRunning() {
+		conn.CloseWithError(ErrorCodeDisconnected, ErrNodeClosed.Error())
+		return ErrNodeClosed
+	}
+
+	// Jeder Client bekommt seinen eigenen Kontext, er wird beim erzwungenen Schließen des Nodes beendet
+	ctx, cancel := context.WithCancelCause(o.ctx)
+	return o._ConnectOutgoing(ctx, cancel, conn, config)
+}
+
+func (o *Node) _ConnectOutgoing(ctx context.Context, cancel context.CancelCauseFunc, conn transport.Conn, config NodeP2PConnectionConfig) error {
+	// Die Verbindung wird Initialisiert
+	nodeConn, err := o._InitNodeConn(ctx, cancel, false, config, 0, conn)
+	if err != nil {
+		cancel(err)
+		return err
+	}
 
 	// Die Verbindung wird vorbereitet
-	if err := _VarsAddNodeConnection(nodeConn); err != nil {
+	if err := o._AddConnection(nodeConn); err != nil {
 		cancel(err)
+		conn.CloseWithError(ErrorCodeDisconnected, err.Error())
 		return err
 	}
 
 	// Die Handler Routine wird gestartet
 	_AsyncHandleConnection(nodeConn, func() {
 		// Die Verbindung wurde getrennt, sie wird aus dem Verbindungsspeicher entfernt
-		_VarsDeleteNodeConnection(nodeConn)
+		o._DeleteConnection(nodeConn)
 	})
 
 	// Falls alles passt, wird eine Verbindung hergestellt (hier Dummy-Rückgabe)
@@ -0,0 +1,98 @@
+package p2p
+
+import (
+	"fmt"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+)
+
+// Gibt die Informationen aller aktiven Verbindungen des Standard Nodes zurück
+func ListConnections() []ConnectionInfo {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return []ConnectionInfo{}
+	}
+	return node.ListConnections()
+}
+
+// Gibt die Informationen einer Verbindung des Standard Nodes zurück
+func GetConnection(id ConnectionId) (ConnectionInfo, error) {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return ConnectionInfo{}, err
+	}
+	return node.GetConnection(id)
+}
+
+// Gibt alle Verbindungen des Standard Nodes zu einer Adresse zurück
+func GetConnectionsByPeer(address *crypto.OpenKeyP2PAddress) []ConnectionInfo {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return []ConnectionInfo{}
+	}
+	return node.GetConnectionsByPeer(address)
+}
+
+// Trennt eine Verbindung des Standard Nodes
+func Disconnect(id ConnectionId, reason string) error {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+	return node.Disconnect(id, reason)
+}
+
+// Gibt die Informationen aller aktiven Verbindungen zurück
+func (o *Node) ListConnections() []ConnectionInfo {
+	connections := o._GetConnections()
+	reval := make([]ConnectionInfo, 0, len(connections))
+	for _, conn := range connections {
+		reval = append(reval, conn.Info())
+	}
+	return reval
+}
+
+// Gibt die Informationen einer Verbindung zurück
+func (o *Node) GetConnection(id ConnectionId) (ConnectionInfo, error) {
+	conn := o._GetConnection(id)
+	if conn == nil {
+		return ConnectionInfo{}, fmt.Errorf("%w: %s", ErrConnectionNotFound, id)
+	}
+	return conn.Info(), nil
+}
+
+// Gibt alle Verbindungen zu einer Adresse zurück, Verbindungen zu bekannten Nachfolgern der Adresse werden ebenfalls zurückgegeben
+func (o *Node) GetConnectionsByPeer(address *crypto.OpenKeyP2PAddress) []ConnectionInfo {
+	connections := o._GetConnectionsByPeer(address)
+	reval := make([]ConnectionInfo, 0, len(connections))
+	for _, conn := range connections {
+		reval = append(reval, conn.Info())
+	}
+	return reval
+}
+
+func (o *Node) _GetConnectionsByPeer(address *crypto.OpenKeyP2PAddress) []*NodeP2PConnection {
+	resolvedAddress := o.ResolveSuccessorAddress(address)
+	reval := make([]*NodeP2PConnection, 0)
+	for _, conn := range o._GetConnections() {
+		peerAddress := conn.GetRemoteAddress()
+		if peerAddress.Equal(address) || o.ResolveSuccessorAddress(peerAddress).Equal(resolvedAddress) {
+			reval = append(reval, conn)
+		}
+	}
+	return reval
+}
+
+// Trennt eine Verbindung, der Grund wird an die Gegenseite übertragen
+func (o *Node) Disconnect(id ConnectionId, reason string) error {
+	conn := o._GetConnection(id)
+	if conn == nil {
+		return fmt.Errorf("%w: %s", ErrConnectionNotFound, id)
+	}
+
+	logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Connection %s closed by local node {%s} %s -> %s", id, reason, conn.localSocketAddress, conn.remoteSocketAddress)
+	conn.contextCancel(fmt.Errorf("%w: %s", ErrConnectionDisconnected, reason))
+	return conn.conn.CloseWithError(ErrorCodeDisconnected, reason)
+}
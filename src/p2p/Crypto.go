@@ -2,30 +2,98 @@ package p2p
 
 import (
 	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
+	"golang.org/x/crypto/curve25519"
 )
 
-func _SignByteSlice(bslice []byte) ([]byte, error) {
-	return nil, nil
+func (o *Node) _SignByteSlice(bslice []byte) ([]byte, error) {
+	signer := o._GetSigner()
+
+	// Es wird ein Hash aus den Daten erzeugt, dieser wird Signiert
+	dataHash, err := crypto.ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, bslice)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := crypto.AddressSign(signer, dataHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return signature.GetRawSignature(), nil
+}
+
+// Prüft die Signatur des Absenders, in revocations widerrufene Absender werden abgelehnt
+func _VerifyByteSliceSignature(revocations *crypto.RevocationStore, signerAddress *crypto.OpenKeyP2PAddress, bslice []byte, signature []byte) (bool, error) {
+	dataHash, err := crypto.ComputeHash(openkeyp2p.DEFAULT_HASH_METHODE_256BIT, bslice)
+	if err != nil {
+		return false, err
+	}
+	return signerAddress.VerifySignatureWithStore(revocations, crypto.OpenKeyP2PSignature(signature), dataHash)
 }
 
 func _BuildRandomVIdValue() (NodeP2PConnectionValidationId, error) {
-	return nil, nil
+	bitvalue, err := _GenerateRandom256BitValue()
+	if err != nil {
+		return nil, err
+	}
+	return NodeP2PConnectionValidationId(bitvalue), nil
 }
 
-func _SignSteamPacketWSigPacket(packet interface{}) ([]byte, error) {
-	return []byte("signature"), nil
+func (o *Node) _SignSteamPacketWSigPacket(packet interface{}) ([]byte, error) {
+	// Das Paket wird Kanonisch Serialisiert, damit beide Seiten denselben Hash erhalten
+	canonicalPacket, err := _SerializeCanonicalSteamPacket(packet)
+	if err != nil {
+		return nil, err
+	}
+	return o._SignByteSlice(canonicalPacket)
 }
 
-func _GetSignerPublicKey() NodePublicSignatureKey {
-	return NodePublicSignatureKey{}
+func _VerifySteamPacketWSigPacket(revocations *crypto.RevocationStore, signerAddress *crypto.OpenKeyP2PAddress, packet interface{}, signature []byte) (bool, error) {
+	canonicalPacket, err := _SerializeCanonicalSteamPacket(packet)
+	if err != nil {
+		return false, err
+	}
+	return _VerifyByteSliceSignature(revocations, signerAddress, canonicalPacket, signature)
 }
 
-func _GetEncryptionPublicKey() NodePublicEncryptionKey {
-	return NodePublicEncryptionKey{}
+// Erzeugt aus dem Signer Key eines Hello Paketes die OpenKeyP2P Adresse der Gegenseite
+func _AddressFromSignerKey(keyType openkeyp2p.OpenKeyP2PKeyType, signerKey NodePublicSignatureKey) (*crypto.OpenKeyP2PAddress, error) {
+	addr, err := crypto.OpenKeyP2PAddressFromKey(keyType, openkeyp2p.OpenKeyP2PPublicKey(signerKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignerKey, err)
+	}
+	return addr, nil
+}
+
+func (o *Node) _GetSignerKeyType() openkeyp2p.OpenKeyP2PKeyType {
+	return o._GetSigner().KeyType()
+}
+
+func (o *Node) _GetSignerPublicKey() NodePublicSignatureKey {
+	return NodePublicSignatureKey(o._GetSigner().PublicKey())
+}
+
+func (o *Node) _GetEncryptionPublicKey() NodePublicEncryptionKey {
+	// Der Curve25519 Schlüssel wird aus dem privaten Schlüssel der Identität abgeleitet
+	curvePrivKey, err := o._GetSigner().CurvePrivateKey()
+	if err != nil {
+		return NodePublicEncryptionKey{}
+	}
+	curvePubKey, err := curve25519.X25519(curvePrivKey, curve25519.Basepoint)
+	if err != nil {
+		return NodePublicEncryptionKey{}
+	}
+	return NodePublicEncryptionKey(curvePubKey)
 }
 
-func _GetCryptoMethodesStatements() NodeP2PCryptoMethode {
-	return "ed25519#curve25519;"
+func (o *Node) _GetCryptoMethodesStatements() NodeP2PCryptoMethode {
+	return NodeP2PCryptoMethode(crypto.KeyTypeName(o._GetSignerKeyType()) + "#curve25519;")
 }
 
 func _GenerateRandom256BitValue() ([]byte, error) {
@@ -36,3 +104,58 @@ func _GenerateRandom256BitValue() ([]byte, error) {
 	}
 	return bytes, nil
 }
+
+// Erzeugt die Ende-zu-Ende Sitzung für einen direkt verbundenen Peer anhand seines angekündigten EncryptionKey
+func (o *Node) _NewPayloadSessionForPeer(peerAddress *crypto.OpenKeyP2PAddress, peerEncryptionKey NodePublicEncryptionKey) (*crypto.PayloadSession, error) {
+	signer := o._GetSigner()
+
+	localAddress, err := crypto.OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	localCurvePriv, err := signer.CurvePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.NewPayloadSessionFromCurveKeys(localAddress, localCurvePriv, peerAddress, peerEncryptionKey)
+}
+
+// Leitet die Adresse der Gegenseite aus dem TLS Zertifikat der Verbindung ab
+func _PeerAddressFromTransportConn(revocations *crypto.RevocationStore, conn transport.Conn) (*crypto.OpenKeyP2PAddress, error) {
+	peerCertificates := conn.PeerCertificates()
+	if len(peerCertificates) != 1 {
+		return nil, crypto.ErrInvalidPeerCertificate
+	}
+	return crypto.PeerAddressFromCertificateWithStore(revocations, peerCertificates[0])
+}
+
+// Erzeugt eine TLS Konfiguration aus der Identität des Standard Nodes, siehe Node.GenerateTLSConfig
+func GenerateTLSConfig(expectedPeer *crypto.OpenKeyP2PAddress) (*tls.Config, error) {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return nil, err
+	}
+	return node.GenerateTLSConfig(expectedPeer)
+}
+
+// Erzeugt eine an die Identität des Nodes gebundene TLS Konfiguration, Widerrufe werden gegen den Store des Nodes
+// geprüft. Sofern expectedPeer angegeben wurde, muss die Gegenseite genau diese Adresse besitzen
+func (o *Node) GenerateTLSConfig(expectedPeer *crypto.OpenKeyP2PAddress) (*tls.Config, error) {
+	return crypto.GenerateNodeTLSConfigWithStore(o.GetRevocationStore(), o._GetSigner(), expectedPeer)
+}
+
+// Gibt die OpenKeyP2P Adresse des Standard Nodes zurück
+func GetLocalNodeAddress() (*crypto.OpenKeyP2PAddress, error) {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return nil, err
+	}
+	return node.GetLocalNodeAddress()
+}
+
+// Gibt die OpenKeyP2P Adresse des Nodes zurück
+func (o *Node) GetLocalNodeAddress() (*crypto.OpenKeyP2PAddress, error) {
+	return crypto.OpenKeyP2PAddressFromSigner(o._GetSigner())
+}
@@ -0,0 +1,77 @@
+package p2p
+
+import (
+	"fmt"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+)
+
+// Bevor eine Verbindung geordnet geschlossen wird, wird ein Goodbye Paket mit einem Grund gesendet:
+//
+//	Header (2 Bytes) | Reason (1 Byte)
+//
+// Die Gegenseite baut die Verbindung daraufhin ab. Erfolgt dies nicht innerhalb von goodbyeLingerTimeout,
+// wird die Verbindung lokal geschlossen.
+
+// Zeit welche der Gegenseite nach dem Goodbye bleibt, die Verbindung selbst zu schließen
+const goodbyeLingerTimeout = 2 * time.Second
+
+type GoodbyeReason uint8
+
+const (
+	GoodbyeReasonUnspecified GoodbyeReason = 0
+	GoodbyeReasonShutdown    GoodbyeReason = 1
+	GoodbyeReasonRestart     GoodbyeReason = 2
+)
+
+// Gibt den Namen des Grundes zurück
+func (o GoodbyeReason) String() string {
+	switch o {
+	case GoodbyeReasonUnspecified:
+		return "unspecified"
+	case GoodbyeReasonShutdown:
+		return "shutdown"
+	case GoodbyeReasonRestart:
+		return "restart"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(o))
+	}
+}
+
+// Stellt das Goodbye Paket als letztes Paket in den Schreibpuffer und schließt diesen,
+// der Writer beendet sich nachdem alle verbleibenden Pakete gesendet wurden
+func _SayGoodbye(conn *NodeP2PConnection, reason GoodbyeReason) error {
+	err := conn.writerControlBuffer.Put(append([]byte(Goodbye[:]), byte(reason)))
+	conn.writerControlBuffer.Close()
+	conn.writerTrafficBuffer.Close()
+	if err != nil {
+		return err
+	}
+
+	// Antwortet die Gegenseite nicht, wird die Verbindung nach Ablauf der Wartezeit geschlossen,
+	// die Routine endet spätestens mit dem Kontext der Verbindung
+	go func() {
+		timer := time.NewTimer(goodbyeLingerTimeout)
+		defer timer.Stop()
+		select {
+		case <-conn.ctx.Done():
+		case <-timer.C:
+			conn.contextCancel(fmt.Errorf("%w: goodbye %s", ErrNodeClosed, reason))
+		}
+	}()
+
+	return nil
+}
+
+func _ProcessGoodbyePacket(conn *NodeP2PConnection, data []byte) error {
+	if len(data) != 3 {
+		return fmt.Errorf("invalid goodbye packet size %d", len(data))
+	}
+
+	reason := GoodbyeReason(data[2])
+	logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Peer said goodbye {%s} %s -> %s", reason, conn.localSocketAddress, conn.remoteSocketAddress)
+	conn.contextCancel(fmt.Errorf("%w: %s", ErrPeerGoodbye, reason))
+	return nil
+}
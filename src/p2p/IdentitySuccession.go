@@ -0,0 +1,270 @@
+package p2p
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+)
+
+// Maximale Länge einer Nachfolge Kette, zugleich die Anzahl der eigenen Nachfolge Einträge welche im Hello
+// Paket mitgesendet werden sowie die Anzahl der Einträge welche aus einem Hello Paket übernommen werden
+const maxSuccessionChainLength = 16
+
+// Maximale Anzahl gespeicherter Nachfolge Einträge, gespeicherte Einträge werden nie verdrängt.
+// Eine Verbindung darf je successionRateWindow höchstens maxSuccessionsPerWindow Einträge senden
+const (
+	maxIdentitySuccessions  = 4096
+	successionRateWindow    = time.Minute
+	maxSuccessionsPerWindow = maxSuccessionChainLength
+)
+
+// Ersetzt die Identität des Nodes durch newSigner. Es wird ein vom alten und neuen Schlüssel signierter
+// Nachfolge Eintrag erzeugt und an alle verbundenen Peers gesendet, zusätzlich wird er in jedem Hello Paket
+// mitgesendet, damit Peers welche nur die alte Adresse kennen die neue Identität akzeptieren.
+// Bestehende Listener dürfen weiterhin das Zertifikat der alten Identität verwenden, Peers welche die
+// Nachfolge bereits kennen akzeptieren bei angepinnter Adresse jedoch nur noch die neue Identität.
+func (o *Node) RotateNodeIdentity(newSigner crypto.OpenKeyP2PSigner) (*crypto.SuccessionRecord, error) {
+	if !o._IsRunning() {
+		return nil, ErrNodeClosed
+	}
+
+	record, err := crypto.NewSuccessionRecord(o._GetSigner(), newSigner)
+	if err != nil {
+		return nil, err
+	}
+
+	recordBytes, err := record.ToByteSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	// Die neue Identität wird übernommen, die Routing Sitzungen gehören zur alten Identität und werden verworfen
+	o.lock.Lock()
+	o.signer = newSigner
+	o.localSuccessionRecords = append(o.localSuccessionRecords, recordBytes)
+	if len(o.localSuccessionRecords) > maxSuccessionChainLength {
+		o.localSuccessionRecords = o.localSuccessionRecords[len(o.localSuccessionRecords)-maxSuccessionChainLength:]
+	}
+	o.routingPayloadSessions = _NewRoutingSessionCache()
+	connections := make([]*NodeP2PConnection, 0, len(o.connections))
+	for _, conn := range o.connections {
+		connections = append(connections, conn)
+	}
+	o.lock.Unlock()
+
+	// Der Eintrag wird an alle verbundenen Peers gesendet
+	packet := append([]byte(IdentitySuccession[:]), recordBytes...)
+	for _, conn := range connections {
+		if err := conn.writerControlBuffer.Put(packet); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by announcing identity succession {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
+	}
+
+	return record, nil
+}
+
+// Ersetzt die Identität des Standard Nodes
+func RotateNodeIdentity(newSigner crypto.OpenKeyP2PSigner) (*crypto.SuccessionRecord, error) {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return nil, err
+	}
+	return node.RotateNodeIdentity(newSigner)
+}
+
+// Löst den Nachfolger einer Adresse über die Nachfolge Einträge des Standard Nodes auf
+func ResolveSuccessorAddress(address *crypto.OpenKeyP2PAddress) *crypto.OpenKeyP2PAddress {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return address
+	}
+	return node.ResolveSuccessorAddress(address)
+}
+
+// Gibt den letzten bekannten Nachfolger einer Adresse zurück, ist keiner bekannt wird die Adresse selbst zurückgegeben
+func (o *Node) ResolveSuccessorAddress(address *crypto.OpenKeyP2PAddress) *crypto.OpenKeyP2PAddress {
+	current := address
+	for i := 0; i < maxSuccessionChainLength; i++ {
+		record := o._GetIdentitySuccession(current)
+		if record == nil {
+			break
+		}
+		successor, err := record.GetNewAddress()
+		if err != nil {
+			break
+		}
+		current = successor
+	}
+	return current
+}
+
+// Gibt an ob successor über bekannte Nachfolge Einträge aus address hervorgeht
+func (o *Node) _IsSuccessorAddress(address *crypto.OpenKeyP2PAddress, successor *crypto.OpenKeyP2PAddress) bool {
+	current := address
+	for i := 0; i < maxSuccessionChainLength; i++ {
+		record := o._GetIdentitySuccession(current)
+		if record == nil {
+			return false
+		}
+		next, err := record.GetNewAddress()
+		if err != nil {
+			return false
+		}
+		if next.Equal(successor) {
+			return true
+		}
+		current = next
+	}
+	return false
+}
+
+// Prüft und speichert einen Nachfolge Eintrag. Ein Eintrag kann mit frisch erzeugten Schlüsseln von jedem
+// kostenlos erstellt werden, er wird daher nur übernommen wenn die alte Adresse im Adressbuch steht oder eine
+// Verbindung zu ihr besteht. Die Signaturen werden erst danach geprüft
+func (o *Node) _AcceptSuccessionRecord(record *crypto.SuccessionRecord) error {
+	oldAddress, err := record.GetOldAddress()
+	if err != nil {
+		return err
+	}
+	if !o._IsKnownPeerAddress(oldAddress) {
+		return fmt.Errorf("%w: unknown address %s", crypto.ErrInvalidSuccessionRecord, oldAddress.ToString())
+	}
+	if err := record.VerifyWithStore(o.GetRevocationStore()); err != nil {
+		return err
+	}
+
+	if err := o._AddIdentitySuccession(oldAddress, record); err != nil {
+		return err
+	}
+
+	// Vertrauensstufe und Endpunkte der alten Adresse gehen auf den Nachfolger über
+	if book := o.GetAddressBook(); book != nil {
+		newAddress, _ := record.GetNewAddress()
+		if err := book.Succeed(oldAddress, newAddress); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by updating address book {%s}", err)
+		}
+	}
+	return nil
+}
+
+// Liest die Nachfolge Einträge aus einem Hello Paket ein ohne sie zu prüfen, nicht lesbare Einträge werden verworfen
+func _DecodeHelloSuccessionRecords(records [][]byte) []*crypto.SuccessionRecord {
+	if len(records) > maxSuccessionChainLength {
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Hello packet contains %d identity successions, only the last %d are accepted", len(records), maxSuccessionChainLength)
+		records = records[len(records)-maxSuccessionChainLength:]
+	}
+	reval := make([]*crypto.SuccessionRecord, 0, len(records))
+	for _, recordBytes := range records {
+		record, err := crypto.DecodeSuccessionRecord(recordBytes)
+		if err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid identity succession in hello packet dropped {%s}", err)
+			continue
+		}
+		reval = append(reval, record)
+	}
+	return reval
+}
+
+// Gibt an ob successor über die Nachfolge Einträge eines Hello Paketes aus address hervorgeht,
+// nur die Einträge der Kette werden geprüft
+func (o *Node) _IsSuccessorInRecords(records []*crypto.SuccessionRecord, address *crypto.OpenKeyP2PAddress, successor *crypto.OpenKeyP2PAddress) bool {
+	current := address
+	for i := 0; i < maxSuccessionChainLength; i++ {
+		var next *crypto.OpenKeyP2PAddress
+		for _, record := range records {
+			if oldAddress, err := record.GetOldAddress(); err != nil || !oldAddress.Equal(current) {
+				continue
+			}
+			if err := record.VerifyWithStore(o.GetRevocationStore()); err != nil {
+				return false
+			}
+			next, _ = record.GetNewAddress()
+			break
+		}
+		if next == nil {
+			return false
+		}
+		if next.Equal(successor) {
+			return true
+		}
+		current = next
+	}
+	return false
+}
+
+// Übernimmt die Nachfolge Einträge aus einem Hello Paket, wird erst nach Arbeitsnachweis und Identitätsprüfung
+// aufgerufen. Einträge unbekannter oder ungültiger Adressen werden verworfen
+func (o *Node) _AcceptSuccessionRecords(records []*crypto.SuccessionRecord) {
+	for _, record := range records {
+		if err := o._AcceptSuccessionRecord(record); err != nil {
+			logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Identity succession in hello packet dropped {%s}", err)
+		}
+	}
+}
+
+// Verarbeitet einen über den Control Stream empfangenen Nachfolge Eintrag
+func _ProcessIdentitySuccessionPacket(conn *NodeP2PConnection, data []byte) error {
+	// Die Anzahl der Einträge je Verbindung wird begrenzt, bevor die Signaturen geprüft werden
+	if !conn._AllowSuccession(time.Now()) {
+		return fmt.Errorf("%w: more than %d successions per %s", ErrSuccessionLimitReached, maxSuccessionsPerWindow, successionRateWindow)
+	}
+
+	record, err := crypto.DecodeSuccessionRecord(data[2:])
+	if err != nil {
+		return err
+	}
+	if err := conn.node._AcceptSuccessionRecord(record); err != nil {
+		return err
+	}
+
+	oldAddress, _ := record.GetOldAddress()
+	newAddress, _ := record.GetNewAddress()
+	logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Identity succession %s -> %s accepted %s -> %s", oldAddress.ToString(), newAddress.ToString(), conn.localSocketAddress, conn.remoteSocketAddress)
+	return nil
+}
+
+// Zählt einen empfangenen Nachfolge Eintrag, wird nur von der Control Stream Reader Routine der Verbindung aufgerufen
+func (o *NodeP2PConnection) _AllowSuccession(now time.Time) bool {
+	if now.Sub(o.successionWindowStart) >= successionRateWindow {
+		o.successionWindowStart = now
+		o.successionCount = 0
+	}
+	if o.successionCount >= maxSuccessionsPerWindow {
+		return false
+	}
+	o.successionCount++
+	return true
+}
+
+// Erzeugt eine Prüffunktion für das TLS Zertifikat einer angepinnten Adresse, es wird nur der letzte bekannte
+// Nachfolger der angepinnten Adresse akzeptiert bzw. die Adresse selbst wenn kein Nachfolger bekannt ist.
+// Vorgänger werden nie akzeptiert, ihr Schlüssel wurde abgelöst und ist möglicherweise kompromittiert
+func (o *Node) _VerifyPinnedPeerCertificate(expectedPeer *crypto.OpenKeyP2PAddress) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(rawCerts) != 1 {
+			return fmt.Errorf("%w: expected exactly one certificate, got %d", crypto.ErrInvalidPeerCertificate, len(rawCerts))
+		}
+
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return fmt.Errorf("%w: %w", crypto.ErrInvalidPeerCertificate, err)
+		}
+		peerAddress, err := crypto.PeerAddressFromCertificateWithStore(o.GetRevocationStore(), cert)
+		if err != nil {
+			return err
+		}
+		if o.IsAddressRevoked(peerAddress) {
+			return fmt.Errorf("%w: %s", crypto.ErrAddressRevoked, peerAddress.ToString())
+		}
+
+		currentPeer := o.ResolveSuccessorAddress(expectedPeer)
+		if peerAddress.Equal(currentPeer) {
+			return nil
+		}
+		return fmt.Errorf("%w: got %s, expected %s", crypto.ErrPeerIdentityMismatch, peerAddress.ToString(), currentPeer.ToString())
+	}
+}
@@ -0,0 +1,318 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
+)
+
+// Keepalive Abstand der Testverbindungen, er liegt deutlich über der Umlaufzeit der Testleitung
+const memoryTestKeepaliveTime = 250 * time.Millisecond
+
+// Wartezeit bis ein erwarteter Zustand spätestens eingetreten sein muss
+const memoryTestTimeout = 10 * time.Second
+
+// Die Testleitung entspricht test/p2p/memory, 20ms Latenz, 5% Verlust und 1 MB/s je Richtung
+var memoryTestLinkConfig = transport.MemoryLinkConfig{
+	Latency:   20 * time.Millisecond,
+	LossRate:  0.05,
+	Bandwidth: 1 << 20,
+}
+
+type _ReceivedTestDatagram struct {
+	source  *crypto.OpenKeyP2PAddress
+	payload []byte
+}
+
+type _MemoryTestPair struct {
+	server        *Node
+	client        *Node
+	serverAddress *crypto.OpenKeyP2PAddress
+	clientAddress *crypto.OpenKeyP2PAddress
+	lock          *sync.Mutex
+	received      []_ReceivedTestDatagram
+}
+
+func _NewMemoryTestNode(tb testing.TB) (*Node, crypto.OpenKeyP2PSigner) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		tb.Fatal(err)
+	}
+	signer, err := crypto.NewSignerFromSeed(openkeyp2p.Type_Ed25519, seed)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	node, err := NewNode(signer)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	node.keepaliveTime = memoryTestKeepaliveTime
+	tb.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		node.Close(ctx)
+	})
+	return node, signer
+}
+
+// Verbindet zwei Nodes über ein Netzwerk im Speicher und wartet bis beide Seiten die Verbindung registriert haben
+func _NewMemoryTestPair(tb testing.TB) *_MemoryTestPair {
+	network, err := transport.NewMemoryNetwork(memoryTestLinkConfig)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	server, _ := _NewMemoryTestNode(tb)
+	client, _ := _NewMemoryTestNode(tb)
+	serverTLSConfig, err := server.GenerateTLSConfig(nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	clientTLSConfig, err := client.GenerateTLSConfig(nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	pair := &_MemoryTestPair{server: server, client: client, lock: new(sync.Mutex)}
+	if pair.serverAddress, err = server.GetLocalNodeAddress(); err != nil {
+		tb.Fatal(err)
+	}
+	if pair.clientAddress, err = client.GetLocalNodeAddress(); err != nil {
+		tb.Fatal(err)
+	}
+	server.SetDatagramHandler(func(source *crypto.OpenKeyP2PAddress, payload []byte) {
+		pair.lock.Lock()
+		defer pair.lock.Unlock()
+		pair.received = append(pair.received, _ReceivedTestDatagram{source: source, payload: bytes.Clone(payload)})
+	})
+
+	listener, err := network.Listen("server", serverTLSConfig)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := server.AddTransportListener(listener, &NodeP2PListenerConfig{}); err != nil {
+		tb.Fatal(err)
+	}
+	conn, err := network.Dial(context.Background(), "server", clientTLSConfig)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := client.ConnectTransport(conn, NewNodeP2PConnectionConfig()); err != nil {
+		tb.Fatal(err)
+	}
+
+	// Die eingehende Verbindung wird auf dem Server erst nach dem Handshake registriert
+	_WaitFor(tb, "connection registered on both nodes", func() bool {
+		return len(client.ListConnections()) == 1 && len(server.ListConnections()) == 1
+	})
+	return pair
+}
+
+func (o *_MemoryTestPair) _Received() []_ReceivedTestDatagram {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return append([]_ReceivedTestDatagram(nil), o.received...)
+}
+
+func _WaitFor(tb testing.TB, what string, condition func() bool) {
+	deadline := time.Now().Add(memoryTestTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			tb.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMemoryTransportHandshake(t *testing.T) {
+	pair := _NewMemoryTestPair(t)
+
+	clientInfo := pair.client.ListConnections()[0]
+	serverInfo := pair.server.ListConnections()[0]
+	if !clientInfo.RemoteAddress.Equal(pair.serverAddress) || clientInfo.Direction != ConnectionDirectionOutgoing {
+		t.Fatalf("client connection does not point to the server: %+v", clientInfo)
+	}
+	if !serverInfo.RemoteAddress.Equal(pair.clientAddress) || serverInfo.Direction != ConnectionDirectionIncoming {
+		t.Fatalf("server connection does not point to the client: %+v", serverInfo)
+	}
+	if !clientInfo.UnreliableDatagrams || !serverInfo.UnreliableDatagrams {
+		t.Fatal("unreliable datagrams were not negotiated")
+	}
+}
+
+func TestMemoryTransportKeepalive(t *testing.T) {
+	pair := _NewMemoryTestPair(t)
+	clientId := pair.client.ListConnections()[0].Id
+	serverId := pair.server.ListConnections()[0].Id
+
+	// Beide Seiten müssen mehrere Keepalive Anfragen beantwortet bekommen
+	var firstClient, firstServer time.Time
+	_WaitFor(t, "first keepalive on both nodes", func() bool {
+		firstClient = pair.client.ListConnections()[0].LastKeepalive
+		firstServer = pair.server.ListConnections()[0].LastKeepalive
+		return !firstClient.IsZero() && !firstServer.IsZero()
+	})
+	_WaitFor(t, "further keepalives on both nodes", func() bool {
+		return pair.client.ListConnections()[0].LastKeepalive.Sub(firstClient) >= 2*memoryTestKeepaliveTime &&
+			pair.server.ListConnections()[0].LastKeepalive.Sub(firstServer) >= 2*memoryTestKeepaliveTime
+	})
+
+	// Die Verbindung muss dabei bestehen bleiben
+	if _, err := pair.client.GetConnection(clientId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pair.server.GetConnection(serverId); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Eine per UpdatePOWDiff erhöhte Schwierigkeit muss weitere Verbindungen des Clients oberhalb seiner Obergrenze verhindern
+func TestMemoryTransportAnnouncedPOWDifficultyLimitsDialing(t *testing.T) {
+	pair := _NewMemoryTestPair(t)
+	pair.client.SetMaxPOWSolveDifficulty(0)
+	clientConn := pair.client._GetConnections()[0]
+
+	// Die Last auf dem Server steigt, die neue Schwierigkeit wird an den Client gesendet
+	for i := 0; i < powLoadThreshold; i++ {
+		pair.server._RecordIncomingPOWAttempt()
+	}
+	_WaitFor(t, "announced pow difficulty on the client", func() bool {
+		return clientConn.GetRemotePOWDifficulty() > 0
+	})
+
+	uri := "quic://" + pair.serverAddress.ToString() + "@127.0.0.1:9"
+	if err := pair.client.ConnectTo(uri, nil, NewNodeP2PConnectionConfig()); !errors.Is(err, ErrInvalidPOWDifficulty) {
+		t.Fatalf("dialing above the pow limit not refused: %v", err)
+	}
+	if err := pair.client._CheckAnnouncedPOWDifficulty(nil, clientConn.remoteSocketAddress); !errors.Is(err, ErrInvalidPOWDifficulty) {
+		t.Fatalf("dialing the same socket address above the pow limit not refused: %v", err)
+	}
+
+	pair.client.SetMaxPOWSolveDifficulty(powMaxAdmissionDifficulty)
+	if err := pair.client._CheckAnnouncedPOWDifficulty(pair.serverAddress, clientConn.remoteSocketAddress); err != nil {
+		t.Fatalf("dialing below the pow limit refused: %v", err)
+	}
+}
+
+func TestMemoryTransportRoutingChannelDatagram(t *testing.T) {
+	pair := _NewMemoryTestPair(t)
+	conn, err := pair.client._GetPeerConnection(pair.serverAddress, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ein Paket für einen anderen Node wird vom Server verworfen, es gibt keine Route
+	otherNode, _ := _NewMemoryTestNode(t)
+	otherAddress, err := otherNode.GetLocalNodeAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreignPacket, err := pair.client._SealRoutingChannelDatagrammPacket(otherAddress, []byte("foreign"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.writerTrafficBuffer.Put(foreignPacket); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ein Paket für den Server wird mit der Adresse des Absenders zugestellt
+	packet, err := pair.client._SealRoutingChannelDatagrammPacket(pair.serverAddress, []byte("routed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.writerTrafficBuffer.Put(packet); err != nil {
+		t.Fatal(err)
+	}
+
+	// Der Traffic Stream ist geordnet, das fremde Paket wurde daher vor dem eigenen verarbeitet
+	_WaitFor(t, "routing channel datagram", func() bool {
+		return len(pair._Received()) > 0
+	})
+	received := pair._Received()
+	if len(received) != 1 {
+		t.Fatalf("received %d datagrams, expected 1", len(received))
+	}
+	if !received[0].source.Equal(pair.clientAddress) || !bytes.Equal(received[0].payload, []byte("routed")) {
+		t.Fatalf("unexpected datagram from %s: %q", received[0].source.ToString(), received[0].payload)
+	}
+
+	// Ein wiederholtes Paket wird vom Replay Fenster der Sitzung verworfen
+	if err := conn.writerTrafficBuffer.Put(packet); err != nil {
+		t.Fatal(err)
+	}
+	if err := pair.client.SendDatagram(pair.serverAddress, []byte("marker")); err != nil {
+		t.Fatal(err)
+	}
+	_WaitFor(t, "marker datagram", func() bool {
+		return len(pair._Received()) > 1
+	})
+	if received := pair._Received(); len(received) != 2 || !bytes.Equal(received[1].payload, []byte("marker")) {
+		t.Fatalf("replayed routing channel datagram was delivered: %d datagrams", len(received))
+	}
+}
+
+func TestMemoryTransportRoutingRejectsRevokedSource(t *testing.T) {
+	pair := _NewMemoryTestPair(t)
+	conn, err := pair.client._GetPeerConnection(pair.serverAddress, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Der Client leitet die Pakete eines nicht direkt verbundenen Nodes an den Server weiter
+	sender, senderKey := _NewMemoryTestNode(t)
+	senderAddress, err := sender.GetLocalNodeAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet, err := sender._SealRoutingChannelDatagrammPacket(pair.serverAddress, []byte("before"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.writerTrafficBuffer.Put(packet); err != nil {
+		t.Fatal(err)
+	}
+	_WaitFor(t, "routing channel datagram", func() bool {
+		return len(pair._Received()) > 0
+	})
+	if received := pair._Received(); !received[0].source.Equal(senderAddress) {
+		t.Fatalf("unexpected source %s", received[0].source.ToString())
+	}
+
+	// Nach dem Widerruf werden weitere Pakete des Absenders verworfen
+	statement, err := crypto.NewSelfRevocation(senderKey, crypto.RevocationReasonKeyCompromise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pair.server.GetRevocationStore().AddRevocation(statement); err != nil {
+		t.Fatal(err)
+	}
+	packet, err = sender._SealRoutingChannelDatagrammPacket(pair.serverAddress, []byte("after"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.writerTrafficBuffer.Put(packet); err != nil {
+		t.Fatal(err)
+	}
+	if err := pair.client.SendDatagram(pair.serverAddress, []byte("marker")); err != nil {
+		t.Fatal(err)
+	}
+	_WaitFor(t, "marker datagram", func() bool {
+		return len(pair._Received()) > 1
+	})
+	if received := pair._Received(); len(received) != 2 || !bytes.Equal(received[1].payload, []byte("marker")) {
+		t.Fatalf("routing channel datagram of a revoked source was delivered: %d datagrams", len(received))
+	}
+
+	// Auch ausgehend wird keine Sitzung zu der widerrufenen Adresse mehr hergestellt
+	if _, err := pair.server._GetRoutingPayloadSession(senderAddress); !errors.Is(err, crypto.ErrAddressRevoked) {
+		t.Fatalf("routing session to a revoked address: %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+)
+
+// Erzeugt einen neuen Node mit der Identität des Signers, der Node läuft bis Close() aufgerufen wird.
+// Der Node erhält einen eigenen leeren RevocationStore, siehe SetRevocationStore
+func NewNode(signer crypto.OpenKeyP2PSigner) (*Node, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("invalid node private key")
+	}
+
+	// Es wird geprüft ob der öffentliche Schlüssel eine gültige Adresse ergibt
+	if _, err := crypto.OpenKeyP2PAddressFromSigner(signer); err != nil {
+		return nil, fmt.Errorf("invalid node private key: %w", err)
+	}
+
+	node := &Node{
+		lock:                   new(sync.Mutex),
+		signer:                 signer,
+		connections:            make(map[ConnectionId]*NodeP2PConnection),
+		routingPayloadSessions: _NewRoutingSessionCache(),
+		identitySuccessions:    make(map[string]*crypto.SuccessionRecord),
+		streamHandlers:         make(map[StreamProtocolId]StreamHandler),
+		revocations:            crypto.NewRevocationStore(),
+		keepaliveTime:          defaultKeepaliveTime,
+		powLoad:                _POWLoadState{windowStart: time.Now()},
+		maxPOWSolveDifficulty:  powMaxAdmissionDifficulty,
+		routines:               new(sync.WaitGroup),
+	}
+	node.ctx, node.cancel = context.WithCancelCause(context.Background())
+	node.shutdownCtx, node.shutdown = context.WithCancel(context.Background())
+
+	// Die Lastmessung für den Arbeitsnachweis wird gestartet
+	node.routines.Add(1)
+	go func() {
+		defer node.routines.Done()
+		node._POWLoadRoutine(node.shutdownCtx)
+	}()
+
+	return node, nil
+}
+
+func (o *Node) _AddConnection(nodeConn *NodeP2PConnection) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.isClosing || o.isClosed {
+		return ErrNodeClosed
+	}
+	o.connections[nodeConn.GetConnectionId()] = nodeConn
+	return nil
+}
+
+func (o *Node) _DeleteConnection(nodeConn *NodeP2PConnection) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	delete(o.connections, nodeConn.GetConnectionId())
+}
+
+func (o *Node) _GetConnection(id ConnectionId) *NodeP2PConnection {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.connections[id]
+}
+
+func (o *Node) _GetConnections() []*NodeP2PConnection {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	reval := make([]*NodeP2PConnection, 0, len(o.connections))
+	for _, conn := range o.connections {
+		reval = append(reval, conn)
+	}
+	return reval
+}
+
+func (o *Node) _GetIncomingConnections() []*NodeP2PConnection {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	reval := make([]*NodeP2PConnection, 0, len(o.connections))
+	for _, conn := range o.connections {
+		if conn.isIncommingConnection {
+			reval = append(reval, conn)
+		}
+	}
+	return reval
+}
+
+// Gibt an ob der Node bereit ist, während Close() ausgeführt wird ist er es nicht mehr
+func (o *Node) _IsRunning() bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return !o.isClosing && !o.isClosed
+}
+
+func (o *Node) _AddListener(listener *NodeP2Listener) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.isClosing || o.isClosed {
+		return ErrNodeClosed
+	}
+	o.listeners = append(o.listeners, listener)
+	return nil
+}
+
+func (o *Node) _GetSigner() crypto.OpenKeyP2PSigner {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.signer
+}
+
+// Gibt den Nachfolger einer über Routing Kanäle erreichbaren Adresse zurück, ist die Adresse oder ihr Nachfolger
+// widerrufen wird ErrAddressRevoked zurückgegeben
+func (o *Node) _ResolveRoutingAddress(address *crypto.OpenKeyP2PAddress) (*crypto.OpenKeyP2PAddress, error) {
+	resolvedAddress := o.ResolveSuccessorAddress(address)
+	for _, item := range []*crypto.OpenKeyP2PAddress{address, resolvedAddress} {
+		if o.IsAddressRevoked(item) {
+			return nil, fmt.Errorf("%w: %s", crypto.ErrAddressRevoked, item.ToString())
+		}
+	}
+	return resolvedAddress, nil
+}
+
+// Gibt die Ende-zu-Ende Sitzung für eine über Routing Kanäle erreichbare Adresse zurück, existiert keine wird sie erzeugt
+func (o *Node) _GetRoutingPayloadSession(peerAddress *crypto.OpenKeyP2PAddress) (*crypto.PayloadSession, error) {
+	o.lock.Lock()
+	sessions := o.routingPayloadSessions
+	signer := o.signer
+	o.lock.Unlock()
+
+	// Adressen mit bekanntem Nachfolger werden auf diesen umgeleitet
+	resolvedAddress, err := o._ResolveRoutingAddress(peerAddress)
+	if err != nil {
+		sessions._Delete(o.ResolveSuccessorAddress(peerAddress).ToString())
+		return nil, err
+	}
+	addrKey := resolvedAddress.ToString()
+	if session, found := sessions._Load(addrKey); found {
+		return session, nil
+	}
+
+	session, err := crypto.NewPayloadSession(signer, resolvedAddress)
+	if err != nil {
+		return nil, err
+	}
+	sessions._RestoreReplayState(addrKey, session)
+
+	return sessions._LoadOrStore(addrKey, session)
+}
+
+// Entschlüsselt einen Routing Frame des Absenders. Existiert noch keine Sitzung, wird eine temporäre Sitzung
+// abgeleitet und erst gespeichert nachdem der Frame authentifiziert wurde, so kann ein Peer mit beliebigen
+// Absender Adressen keine Sitzungen anlegen
+func (o *Node) _OpenRoutingPayloadFrame(sourceAddress *crypto.OpenKeyP2PAddress, frame []byte, additionalData []byte) ([]byte, error) {
+	o.lock.Lock()
+	sessions := o.routingPayloadSessions
+	signer := o.signer
+	o.lock.Unlock()
+
+	// Frames widerrufener Absender werden nicht geöffnet, ihre Sitzung wird verworfen
+	resolvedAddress, err := o._ResolveRoutingAddress(sourceAddress)
+	if err != nil {
+		sessions._Delete(o.ResolveSuccessorAddress(sourceAddress).ToString())
+		return nil, err
+	}
+	addrKey := resolvedAddress.ToString()
+	if session, found := sessions._Load(addrKey); found {
+		return session.Open(frame, additionalData)
+	}
+
+	// Die Replay Einträge einer zuvor verdrängten Sitzung werden vor dem Öffnen übernommen
+	session, err := crypto.NewPayloadSession(signer, resolvedAddress)
+	if err != nil {
+		return nil, err
+	}
+	sessions._RestoreReplayState(addrKey, session)
+	payload, err := session.Open(frame, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wurde zwischenzeitlich eine andere Sitzung gespeichert, muss der Frame auch deren Replay Fenster passieren
+	stored, err := sessions._LoadOrStore(addrKey, session)
+	if err != nil {
+		return nil, err
+	}
+	if stored != session {
+		return stored.Open(frame, additionalData)
+	}
+	return payload, nil
+}
+
+// Speichert einen geprüften Nachfolge Eintrag, die Routing Sitzung der alten Adresse wird verworfen
+func (o *Node) _AddIdentitySuccession(oldAddress *crypto.OpenKeyP2PAddress, record *crypto.SuccessionRecord) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	// Es gilt der zuerst bekannte Nachfolger, ein abweichender Eintrag wird abgelehnt
+	addrKey := oldAddress.ToString()
+	if known, found := o.identitySuccessions[addrKey]; found {
+		if bytes.Equal(known.NewAddress, record.NewAddress) {
+			return nil
+		}
+		return ErrSuccessionConflict
+	}
+
+	if len(o.identitySuccessions) >= maxIdentitySuccessions {
+		return fmt.Errorf("%w: %d successions stored", ErrSuccessionLimitReached, len(o.identitySuccessions))
+	}
+
+	o.identitySuccessions[addrKey] = record
+	o.routingPayloadSessions._Delete(addrKey)
+	return nil
+}
+
+func (o *Node) _GetIdentitySuccession(address *crypto.OpenKeyP2PAddress) *crypto.SuccessionRecord {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.identitySuccessions[address.ToString()]
+}
+
+func (o *Node) _GetLocalSuccessionRecords() [][]byte {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.localSuccessionRecords
+}
+
+// Zählt einen eingehenden Verbindungsversuch und gibt die lastabhängige Zusatzschwierigkeit zurück,
+// changed gibt an ob sich die Zusatzschwierigkeit dadurch geändert hat
+func (o *Node) _RecordPOWAttempt() (extra uint8, changed bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.powLoad.currentAttempts++
+	return o.powLoad._Refresh(time.Now())
+}
+
+// Aktualisiert das Lastfenster ohne einen Verbindungsversuch zu zählen
+func (o *Node) _RefreshPOWLoad() (extra uint8, changed bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.powLoad._Refresh(time.Now())
+}
+
+func (o *Node) _GetPOWLoadDifficulty() uint8 {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.powLoad.extraDifficulty
+}
@@ -10,6 +10,9 @@ import (
 )
 
 func _SyncHandleConnection(conn *NodeP2PConnection) {
+	// Die Verbindung wird in jedem Fall vollständig abgebaut bevor die Funktion zurückkehrt
+	defer _TeardownNodeConnection(conn)
+
 	// Die Waitgroup wird erzeugt
 	wg := new(sync.WaitGroup)
 	wg.Add(2)
@@ -46,6 +49,11 @@ func _SyncHandleConnection(conn *NodeP2PConnection) {
 	logtxt = fmt.Sprintf("%s\n   -> Version: %s", logtxt, openkeyp2p.ParseVersion(conn.controlStream.GetDestinationVersion()))
 	logtxt = fmt.Sprintf("%s\n   -> CMTU: %d", logtxt, conn.controlStream.GetMTU())
 	logtxt = fmt.Sprintf("%s\n   -> ACK-Peer-Packet: %t", logtxt, conn.controlStream.GetACKPeerPacket())
+	if conn.unreliablePayloadSession != nil {
+		logtxt = fmt.Sprintf("%s\n   -> Unreliable Datagrams: Enabled (%d bytes)", logtxt, conn._MaxUnreliableDatagramPayloadSize())
+	} else {
+		logtxt = logtxt + "\n   -> Unreliable Datagrams: Disabled"
+	}
 	if conn.config.HasConfigEntryWithValue("auto-routing", "yes") {
 		logtxt = logtxt + "\n   -> AutoRouting: Enabled"
 	} else {
@@ -68,8 +76,27 @@ func _SyncHandleConnection(conn *NodeP2PConnection) {
 }
 
 func _AsyncHandleConnection(conn *NodeP2PConnection, callback func()) {
+	conn.node.routines.Add(1)
 	go func() {
+		defer conn.node.routines.Done()
 		_SyncHandleConnection(conn)
 		callback()
 	}()
 }
+
+// Beendet den Kontext, schließt den Schreibpuffer sowie die Quic Verbindung und wartet bis
+// die Reader, Writer und Keepalive Routinen der Verbindung beendet wurden
+func _TeardownNodeConnection(conn *NodeP2PConnection) {
+	conn.contextCancel(ErrConnectionDisconnected)
+	conn.writerControlBuffer.Close()
+	conn.writerTrafficBuffer.Close()
+
+	reason := "connection closed"
+	if cause := context.Cause(conn.ctx); cause != nil {
+		reason = cause.Error()
+	}
+	conn.conn.CloseWithError(ErrorCodeDisconnected, reason)
+
+	conn.routines.Wait()
+	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "All routines of connection %s stopped %s -> %s", conn.id, conn.localSocketAddress, conn.remoteSocketAddress)
+}
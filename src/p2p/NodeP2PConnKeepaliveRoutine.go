@@ -9,6 +9,9 @@ import (
 	"github.com/ms2sh/OpenKeyP2P/src/logging"
 )
 
+// Abstand in dem jede Verbindung ein Keepalive Paket sendet, bleibt die Antwort aus wird der Abstand halbiert
+const defaultKeepaliveTime = 12 * time.Second
+
 func _StartKeepaliveRoutinesForNodeConn(conn *NodeP2PConnection, wg *sync.WaitGroup) error {
 	// Die Parameter werden gepfüft
 	if conn == nil || conn.conn == nil {
@@ -22,7 +25,10 @@ func _StartKeepaliveRoutinesForNodeConn(conn *NodeP2PConnection, wg *sync.WaitGr
 	}
 
 	// Wird als Routine ausgeführt
+	conn.routines.Add(1)
 	go func(conn *NodeP2PConnection) {
+		defer conn.routines.Done()
+
 		ticker := time.NewTicker(conn.keepaliveTime) // Der Time wartet bis neue Daten gesendet werden
 		wasChangesTickerTime := false                // Gibt an das die Zeit des Tickers verändert wurde
 		currentKeepaliveInterval := conn.keepaliveTime
@@ -61,6 +67,9 @@ func _StartKeepaliveRoutinesForNodeConn(conn *NodeP2PConnection, wg *sync.WaitGr
 					continue
 				}
 
+				// Der Zeitpunkt der letzten beantworteten Keepalive Anfrage wird festgehalten
+				conn.lastKeepalive.Store(time.Now().UnixNano())
+
 				// Sollte die Tickerzeit verädnert wurden sein, wird sie auf den Standrdwert zurückgesetzt
 				if wasChangesTickerTime {
 					ticker.Stop()
@@ -32,8 +32,23 @@ func _ControlStreamReaderProcess(conn *NodeP2PConnection, wg *sync.WaitGroup, da
 		}
 	case bytes.Equal(data[:2], RoutingChannelCrawler[:]):
 	case bytes.Equal(data[:2], UpdatePOWDiff[:]):
+		if err := _ProcessUpdatePOWDiffPacket(conn, data); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid pow difficulty update dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
 	case bytes.Equal(data[:2], UpdateAutoRoutingQuickSearchTable[:]):
 	case bytes.Equal(data[:2], PeerDiscovery[:]):
+	case bytes.Equal(data[:2], IdentitySuccession[:]):
+		if err := _ProcessIdentitySuccessionPacket(conn, data); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid identity succession dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
+	case bytes.Equal(data[:2], Goodbye[:]):
+		if err := _ProcessGoodbyePacket(conn, data); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid goodbye dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
+	case bytes.Equal(data[:2], Revocation[:]):
+		if err := _ProcessRevocationPacket(conn, data); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid revocation dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
 	default:
 		fmt.Println("unkown packet type")
 		return nil
@@ -52,7 +67,13 @@ func _PackageTrafficStreamReaderProcess(conn *NodeP2PConnection, wg *sync.WaitGr
 	// Es wird versucht zu ermitteln um was für ein Pakettypen es sich handelt
 	switch {
 	case bytes.Equal(data[:2], Datagramm[:]):
+		if err := _ProcessDatagrammPacket(conn, data); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid datagramm dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
 	case bytes.Equal(data[:2], RoutingChannelDatagramm[:]):
+		if err := _ProcessRoutingChannelDatagrammPacket(conn, data); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid routing channel datagramm dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
 	default:
 		fmt.Println("unkown packet type")
 		return nil
@@ -61,6 +82,19 @@ func _PackageTrafficStreamReaderProcess(conn *NodeP2PConnection, wg *sync.WaitGr
 	return nil
 }
 
+// Verarbeitet ein unzuverlässiges Datagramm, fehlerhafte Datagramme werden verworfen ohne die Verbindung zu schließen
+func _DatagramReaderProcess(conn *NodeP2PConnection, data []byte) {
+	// Die Paketgröße wird geprüft
+	if len(data) < 2 || !bytes.Equal(data[:2], UnreliableDatagramm[:]) {
+		logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Unkown unreliable datagramm dropped %s -> %s", conn.localSocketAddress, conn.remoteSocketAddress)
+		return
+	}
+
+	if err := _ProcessUnreliableDatagrammPacket(conn, data); err != nil {
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Invalid unreliable datagramm dropped {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+	}
+}
+
 func _ControlStreamReaderRoutineRootFunction(conn *NodeP2PConnection, wg *sync.WaitGroup) {
 	// Gib an ob die Initalisierung durchgeführt wurde
 	wasinited := false
@@ -173,16 +207,79 @@ func _TrafficStreamReaderRoutineRootFunction(conn *NodeP2PConnection, wg *sync.W
 	}
 }
 
+func _DatagramReaderRoutineRootFunction(conn *NodeP2PConnection, wg *sync.WaitGroup) {
+	// Wird ausgeführt wenn die Funktion am ende ist
+	defer func() {
+		// LOG
+		logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Datagram reader routine stopped %s -> %s", conn.localSocketAddress, conn.remoteSocketAddress)
+	}()
+
+	// Log
+	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Datagram reader routine started %s -> %s", conn.localSocketAddress, conn.remoteSocketAddress)
+
+	// Es wird Signalisiert das der Reader vollständig ausgeführt wird
+	wg.Done()
+
+	// Die Schleife wird verwendet um Eintreffende Datagramme zu lesen
+	for {
+		data, err := conn.conn.ReceiveDatagram(conn.ctx)
+		if err != nil {
+			// Wurde der Kontext geschlossen, wird die Routine ohne Fehler beendet
+			if conn.ctx.Err() != nil {
+				return
+			}
+
+			// LOG
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by reading unreliable datagramm {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+
+			// Der Fehler wird an den Context übergeben
+			conn.contextCancel(fmt.Errorf("_DatagramReaderRoutineRootFunction: %s", err))
+			return
+		}
+
+		// Das Datagramm wird verarbeitet
+		_DatagramReaderProcess(conn, data)
+	}
+}
+
 func _StartReaderRoutinesForNodeConn(conn *NodeP2PConnection, wg *sync.WaitGroup) error {
+	// Neben Control und Traffic Stream werden Anwendungs Streams angenommen, sofern unzuverlässige
+	// Datagramme ausgehandelt wurden, wird ein zusätzlicher Reader benötigt
+	readerCount := 3
+	if conn.unreliablePayloadSession != nil {
+		readerCount = 4
+	}
+
 	// Es wird eine Waiting Group erzeugt
 	wgt := new(sync.WaitGroup)
-	wgt.Add(2)
+	wgt.Add(readerCount)
 
 	// Der Reader für den Controlstream wird gestartet
-	go _ControlStreamReaderRoutineRootFunction(conn, wgt)
+	conn.routines.Add(readerCount)
+	go func() {
+		defer conn.routines.Done()
+		_ControlStreamReaderRoutineRootFunction(conn, wgt)
+	}()
 
 	// Der Reader für den Trafficstream wird gestartet
-	go _TrafficStreamReaderRoutineRootFunction(conn, wgt)
+	go func() {
+		defer conn.routines.Done()
+		_TrafficStreamReaderRoutineRootFunction(conn, wgt)
+	}()
+
+	// Die Routine welche Anwendungs Streams annimmt wird gestartet
+	go func() {
+		defer conn.routines.Done()
+		_ApplicationStreamAcceptRoutineRootFunction(conn, wgt)
+	}()
+
+	// Der Reader für unzuverlässige Datagramme wird gestartet
+	if conn.unreliablePayloadSession != nil {
+		go func() {
+			defer conn.routines.Done()
+			_DatagramReaderRoutineRootFunction(conn, wgt)
+		}()
+	}
 
 	// Es wird darauf gewartet dass beide Routinen ausgeführt werden
 	wgt.Wait()
@@ -23,7 +23,10 @@ func _ControlStreamWriterRoutineRootFunction(conn *NodeP2PConnection, wg *sync.W
 			// Es wird geprüft ob neue Daten verfügbar sind, wenn ja werden diese gesendet
 			data, err := conn.writerControlBuffer.Get()
 			if err != nil {
-				conn.contextCancel(err)
+				// Wurde der Puffer zum Verabschieden geschlossen, wurden alle Pakete gesendet
+				if !conn.writerControlBuffer.IsClosed() {
+					conn.contextCancel(err)
+				}
 				return
 			}
 
@@ -37,7 +40,35 @@ func _ControlStreamWriterRoutineRootFunction(conn *NodeP2PConnection, wg *sync.W
 }
 
 func _TrafficStreamWriterRoutineRootFunction(conn *NodeP2PConnection, wg *sync.WaitGroup) {
-	wg.Done()
+	wasinited := false
+	for {
+		select {
+		case <-conn.ctx.Done(): // Abbruch, wenn der Kontext geschlossen wurde
+			return
+		default:
+			// Es darf nur 1x ein Init Signal gesendet werden
+			if !wasinited {
+				wasinited = true
+				wg.Done()
+			}
+
+			// Es wird gewartet bis ein Datagramm zum Senden bereit steht
+			data, err := conn.writerTrafficBuffer.Get()
+			if err != nil {
+				// Wurde der Puffer zum Verabschieden geschlossen, wurden alle Datagramme gesendet
+				if !conn.writerTrafficBuffer.IsClosed() {
+					conn.contextCancel(err)
+				}
+				return
+			}
+
+			// Die Daten werden geschrieben
+			if err := conn.packageTrafficStream.WriteBytes(data.([]byte)); err != nil {
+				conn.contextCancel(err)
+				return
+			}
+		}
+	}
 }
 
 func _StartWriterRoutinesForNodeConn(conn *NodeP2PConnection, wg *sync.WaitGroup) error {
@@ -46,10 +77,17 @@ func _StartWriterRoutinesForNodeConn(conn *NodeP2PConnection, wg *sync.WaitGroup
 	wgt.Add(2)
 
 	// Der Reader für den Controlstream wird gestartet
-	go _ControlStreamWriterRoutineRootFunction(conn, wgt)
+	conn.routines.Add(2)
+	go func() {
+		defer conn.routines.Done()
+		_ControlStreamWriterRoutineRootFunction(conn, wgt)
+	}()
 
 	// Der Reader für den Trafficstream wird gestartet
-	go _TrafficStreamWriterRoutineRootFunction(conn, wgt)
+	go func() {
+		defer conn.routines.Done()
+		_TrafficStreamWriterRoutineRootFunction(conn, wgt)
+	}()
 
 	// Es wird darauf gewartet dass beide Routinen ausgeführt werden
 	wgt.Wait()
@@ -1,5 +1,59 @@
 package p2p
 
+import (
+	"crypto/rand"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+)
+
+// Länge der zufälligen Verbindungs ID in Bytes
+const connectionIdSize = 16
+
+// Erzeugt eine neue zufällige Verbindungs ID
+func _NewConnectionId() (ConnectionId, error) {
+	idBytes := make([]byte, connectionIdSize)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", err
+	}
+	return ConnectionId(openkeyp2p.Base32Encoding.EncodeToString(idBytes)), nil
+}
+
 func (o *NodeP2PConnection) GetConnectionId() ConnectionId {
-	return ""
+	return o.id
+}
+
+// Gibt die Adresse der Gegenseite zurück
+func (o *NodeP2PConnection) GetRemoteAddress() *crypto.OpenKeyP2PAddress {
+	return o.controlStream.GetDestinationAddress()
+}
+
+// Gibt die Informationen zur Verbindung zurück
+func (o *NodeP2PConnection) Info() ConnectionInfo {
+	direction := ConnectionDirectionOutgoing
+	if o.isIncommingConnection {
+		direction = ConnectionDirectionIncoming
+	}
+
+	// Bis zur ersten beantworteten Keepalive Anfrage bleibt der Zeitpunkt leer
+	var lastKeepalive time.Time
+	if unixNano := o.lastKeepalive.Load(); unixNano != 0 {
+		lastKeepalive = time.Unix(0, unixNano)
+	}
+
+	return ConnectionInfo{
+		Id:                  o.id,
+		RemoteAddress:       o.GetRemoteAddress(),
+		LocalSocketAddress:  o.localSocketAddress,
+		RemoteSocketAddress: o.remoteSocketAddress,
+		Version:             o.controlStream.GetDestinationVersion(),
+		CMTU:                o.controlStream.GetMTU(),
+		UnreliableDatagrams: o.unreliablePayloadSession != nil,
+		Config:              o.config,
+		Direction:           direction,
+		EstablishedAt:       o.establishedAt,
+		Uptime:              time.Since(o.establishedAt),
+		LastKeepalive:       lastKeepalive,
+	}
 }
@@ -2,60 +2,51 @@ package p2p
 
 import (
 	"context"
+	"fmt"
 	"net"
-	"strconv"
 
 	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
-	"github.com/quic-go/quic-go"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
 )
 
-func _TryOpenP2PConnectionControlStream(localhostNetworkInterface *net.Interface, isIncommingConnection bool, conn quic.Connection, config NodeP2PConnectionConfig, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtx context.Context, connCtxCancel context.CancelCauseFunc) (*NodeP2PControlStream, error) {
-	// IP und Port extrahieren
-	host, portStr, err := net.SplitHostPort(conn.RemoteAddr().String())
-	if err != nil {
-		return nil, err
-	}
-
-	// Port in uint16 umwandeln
-	port, err := strconv.ParseUint(portStr, 10, 16)
-	if err != nil {
-		return nil, err
-	}
-
-	// IP-Adresse in Bytes umwandeln
-	ip := net.ParseIP(host)
-	if ip == nil {
-		return nil, err
-	}
-
-	// IPv4 oder IPv6 als Bytes ausgeben
+func (o *Node) _TryOpenP2PConnectionControlStream(isIncommingConnection bool, conn transport.Conn, config NodeP2PConnectionConfig, powChallenge *L1POWChallenge, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtx context.Context, connCtxCancel context.CancelCauseFunc) (*NodeP2PControlStream, error) {
+	// IP und Port der Gegenseite werden extrahiert, Transporte ohne IP Adressen (z.B. im Speicher) übertragen keine
 	var ipBytes []byte
-	var mtu int
-	if ip4 := ip.To4(); ip4 != nil {
-		ipBytes = ip4 // IPv4 als 4-Byte Array
-		mtu = openkeyp2p.CalculateQUICPayloadSize(localhostNetworkInterface.MTU, false)
-	} else {
-		ipBytes = ip.To16() // IPv6 als 16-Byte Array
-		mtu = openkeyp2p.CalculateQUICPayloadSize(localhostNetworkInterface.MTU, true)
+	var port uint64
+	if udpAddr, isUDP := conn.RemoteAddr().(*net.UDPAddr); isUDP {
+		port = uint64(udpAddr.Port)
+		if ip4 := udpAddr.IP.To4(); ip4 != nil {
+			ipBytes = ip4 // IPv4 als 4-Byte Array
+		} else {
+			ipBytes = udpAddr.IP.To16() // IPv6 als 16-Byte Array
+		}
 	}
 
+	// Die MTU wird vom Transport anhand des Netzwerkinterfaces bestimmt
+	mtu := conn.MTU()
+
 	// Das Hello Packet wird erzeugt und in Bytes umgewandelt
 	helloPacketWithoutSignature := L1HelloControlSteamPacketWSig{
 		LocalVersion:       openkeyp2p.Version,
 		SupportedVersions:  openkeyp2p.SUPPORTED_VERSION,
 		NodeConfigOptions:  config,
-		SignerKey:          _GetSignerPublicKey(),
-		EncryptionKey:      _GetEncryptionPublicKey(),
+		SignerKeyType:      o._GetSignerKeyType(),
+		SignerKey:          o._GetSignerPublicKey(),
+		SuccessionRecords:  o._GetLocalSuccessionRecords(),
+		POWChallenge:       powChallenge,
+		EncryptionKey:      o._GetEncryptionPublicKey(),
 		YourIpPort:         NodeP2PAdressPort(port),
 		YourIpAddress:      NodeP2PIpAddress(ipBytes),
-		CryptoKeyMethod:    _GetCryptoMethodesStatements(),
+		CryptoKeyMethod:    o._GetCryptoMethodesStatements(),
 		CMTU:               uint16(mtu),
 		ACKPerPackage:      false,
 		MaxPacketPerSecond: 0,
+		DatagramSupport:    conn.SupportsDatagrams(),
 	}
 
 	// Das Paket wird Signiert und zurückgegeben
-	signature, err := _SignSteamPacketWSigPacket(&helloPacketWithoutSignature)
+	signature, err := o._SignSteamPacketWSigPacket(&helloPacketWithoutSignature)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +78,8 @@ func _TryOpenP2PConnectionControlStream(localhostNetworkInterface *net.Interface
 	return controlStream, nil
 }
 
+// Liest das Hello Paket der Gegenseite ein, die Signatur wird erst von _VerifyHello geprüft, damit eingehende
+// Verbindungen vor der Prüfung den Arbeitsnachweis erbringen können
 func _TypeControlStreamFromBidirectionalStream(bidstr *QuicBidirectionalStream) (*NodeP2PControlStream, error) {
 	// Es wird versucht die Hello Stream Nachricht einzulesen
 	helloStreamMessage, err := _DeserializeHelloControlSteamPacket(bidstr._recivedHelloBytePacket)
@@ -97,6 +90,41 @@ func _TypeControlStreamFromBidirectionalStream(bidstr *QuicBidirectionalStream)
 	return &NodeP2PControlStream{QuicBidirectionalStream: bidstr, destPeerHelloPacket: helloStreamMessage}, nil
 }
 
+// Prüft die Signatur des Hello Paketes der Gegenseite, erst danach ist die Adresse der Gegenseite bekannt
+func (o *NodeP2PControlStream) _VerifyHello(revocations *crypto.RevocationStore) error {
+	// Die Adresse der Gegenseite wird aus dem Signer Key erzeugt
+	destPeerAddress, err := _AddressFromSignerKey(o.destPeerHelloPacket.SignerKeyType, o.destPeerHelloPacket.SignerKey)
+	if err != nil {
+		return err
+	}
+
+	// Die Signatur des Hello Paketes wird geprüft
+	isValid, err := _VerifySteamPacketWSigPacket(revocations, destPeerAddress, &o.destPeerHelloPacket.L1HelloControlSteamPacketWSig, o.destPeerHelloPacket.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidHelloSignature, err)
+	}
+	if !isValid {
+		return ErrInvalidHelloSignature
+	}
+
+	o.destPeerAddress = destPeerAddress
+	return nil
+}
+
+// Gibt die Adresse der Gegenseite zurück, nil solange das Hello Paket nicht geprüft wurde
+func (o *NodeP2PControlStream) GetDestinationAddress() *crypto.OpenKeyP2PAddress {
+	return o.destPeerAddress
+}
+
+func (o *NodeP2PControlStream) GetDestinationSuccessionRecords() [][]byte {
+	return o.destPeerHelloPacket.SuccessionRecords
+}
+
+// Gibt die Challenge des Listeners zurück, nil sofern kein Arbeitsnachweis verlangt wird
+func (o *NodeP2PControlStream) GetDestinationPOWChallenge() *L1POWChallenge {
+	return o.destPeerHelloPacket.POWChallenge
+}
+
 func (o *NodeP2PControlStream) GetDestinationVersion() openkeyp2p.OpenKeyP2PVesion {
 	return o.destPeerHelloPacket.LocalVersion
 }
@@ -113,10 +141,19 @@ func (o *NodeP2PControlStream) GetMyLocalPortByAnotherPeer() NodeP2PAdressPort {
 	return o.destPeerHelloPacket.YourIpPort
 }
 
+func (o *NodeP2PControlStream) GetDestinationEncryptionKey() NodePublicEncryptionKey {
+	return o.destPeerHelloPacket.EncryptionKey
+}
+
 func (o *NodeP2PControlStream) GetMTU() uint16 {
 	return o.destPeerHelloPacket.CMTU
 }
 
+// Gibt an ob die Gegenseite unzuverlässige Datagramme angeboten hat
+func (o *NodeP2PControlStream) GetDatagramSupport() bool {
+	return o.destPeerHelloPacket.DatagramSupport
+}
+
 func (o *NodeP2PControlStream) GetACKPeerPacket() bool {
 	return o.destPeerHelloPacket.ACKPerPackage
 }
@@ -0,0 +1,295 @@
+package p2p
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
+)
+
+// Maximale Größe des Payloads eines Datagramms
+const MaxDatagramPayloadSize = 64 * 1024
+
+// Typ und Länge des QUIC DATAGRAM Frames welche zusätzlich in der CMTU Platz finden müssen
+const quicDatagramFrameOverhead = 3
+
+// Anzahl der Datagramme welche je Verbindung auf das Senden warten können, ist der Puffer voll blockiert SendDatagram
+const trafficWriterBufferSize = 256
+
+// Wird für jedes empfangene Datagramm aufgerufen, source ist die geprüfte Identität des Absenders.
+// Der Handler wird von der Leseroutine der Verbindung aufgerufen, solange er läuft werden auf dieser
+// Verbindung keine weiteren Datagramme gelesen
+type DatagramHandler func(source *crypto.OpenKeyP2PAddress, payload []byte)
+
+// Sendet ein Datagramm über den Standard Node an einen direkt verbundenen Peer
+func SendDatagram(peer *crypto.OpenKeyP2PAddress, payload []byte) error {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+	return node.SendDatagram(peer, payload)
+}
+
+// Sendet ein Datagramm über den Standard Node unzuverlässig an einen direkt verbundenen Peer
+func SendUnreliableDatagram(peer *crypto.OpenKeyP2PAddress, payload []byte) error {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+	return node.SendUnreliableDatagram(peer, payload)
+}
+
+// Gibt die maximale Größe eines unzuverlässigen Datagramms an einen Peer des Standard Nodes zurück
+func MaxUnreliableDatagramPayloadSize(peer *crypto.OpenKeyP2PAddress) (int, error) {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return 0, err
+	}
+	return node.MaxUnreliableDatagramPayloadSize(peer)
+}
+
+// Legt den Handler für empfangene Datagramme des Standard Nodes fest
+func SetDatagramHandler(handler DatagramHandler) error {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+	node.SetDatagramHandler(handler)
+	return nil
+}
+
+// Sendet ein verschlüsseltes Datagramm über den Traffic Stream an einen direkt verbundenen Peer,
+// bestehen mehrere Verbindungen wird die älteste verwendet. Ist der Schreibpuffer der Verbindung voll,
+// wird gewartet bis wieder Platz ist oder die Verbindung geschlossen wurde
+func (o *Node) SendDatagram(peer *crypto.OpenKeyP2PAddress, payload []byte) error {
+	if !o._IsRunning() {
+		return ErrNodeClosed
+	}
+	if len(payload) > MaxDatagramPayloadSize {
+		return fmt.Errorf("%w: %d bytes, maximum %d bytes", ErrDatagramTooLarge, len(payload), MaxDatagramPayloadSize)
+	}
+
+	conn, err := o._GetPeerConnection(peer, false)
+	if err != nil {
+		return err
+	}
+
+	packet, err := _SealDatagrammPacket(conn, payload)
+	if err != nil {
+		return fmt.Errorf("SendDatagram: %w", err)
+	}
+	if err := conn.writerTrafficBuffer.Put(packet); err != nil {
+		return fmt.Errorf("%w: %w", ErrConnectionDisconnected, err)
+	}
+	return nil
+}
+
+// Sendet ein Datagramm als QUIC DATAGRAM Frame (RFC 9221), es wird nicht erneut gesendet wenn es verloren geht
+// und kann in anderer Reihenfolge eintreffen, dafür blockiert ein Verlust keine nachfolgenden Datagramme.
+// Die Gegenseite muss unzuverlässige Datagramme beim Verbindungsaufbau angeboten haben, der Payload darf
+// höchstens MaxUnreliableDatagramPayloadSize Bytes groß sein
+func (o *Node) SendUnreliableDatagram(peer *crypto.OpenKeyP2PAddress, payload []byte) error {
+	if !o._IsRunning() {
+		return ErrNodeClosed
+	}
+
+	conn, err := o._GetPeerConnection(peer, true)
+	if err != nil {
+		return err
+	}
+	if maxSize := conn._MaxUnreliableDatagramPayloadSize(); len(payload) > maxSize {
+		return fmt.Errorf("%w: %d bytes, maximum %d bytes", ErrDatagramTooLarge, len(payload), maxSize)
+	}
+
+	frame, err := conn.unreliablePayloadSession.Seal(payload, UnreliableDatagramm[:])
+	if err != nil {
+		return fmt.Errorf("SendUnreliableDatagram: %w", err)
+	}
+	if err := conn.conn.SendDatagram(append(bytes.Clone(UnreliableDatagramm[:]), frame...)); err != nil {
+		if errors.Is(err, transport.ErrDatagramTooLarge) {
+			return fmt.Errorf("%w: %w", ErrDatagramTooLarge, err)
+		}
+		return fmt.Errorf("%w: %w", ErrConnectionDisconnected, err)
+	}
+	return nil
+}
+
+// Gibt die maximale Größe eines unzuverlässigen Datagramms an den Peer zurück, sie ergibt sich aus der kleineren CMTU beider Seiten
+func (o *Node) MaxUnreliableDatagramPayloadSize(peer *crypto.OpenKeyP2PAddress) (int, error) {
+	conn, err := o._GetPeerConnection(peer, true)
+	if err != nil {
+		return 0, err
+	}
+	return conn._MaxUnreliableDatagramPayloadSize(), nil
+}
+
+// Gibt die älteste noch aktive Verbindung zum Peer zurück, bei unreliable muss die Verbindung unzuverlässige Datagramme unterstützen
+func (o *Node) _GetPeerConnection(peer *crypto.OpenKeyP2PAddress, unreliable bool) (*NodeP2PConnection, error) {
+	var conn *NodeP2PConnection
+	foundAny := false
+	for _, item := range o._GetConnectionsByPeer(peer) {
+		if item.ctx.Err() != nil {
+			continue
+		}
+		foundAny = true
+		if unreliable && item.unreliablePayloadSession == nil {
+			continue
+		}
+		if conn == nil || item.establishedAt.Before(conn.establishedAt) {
+			conn = item
+		}
+	}
+	if conn != nil {
+		return conn, nil
+	}
+	if foundAny {
+		return nil, fmt.Errorf("%w: %s", ErrNoUnreliableDatagrams, peer.ToString())
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPeerNotConnected, peer.ToString())
+}
+
+func (o *NodeP2PConnection) _MaxUnreliableDatagramPayloadSize() int {
+	return max(o.datagramMTU-quicDatagramFrameOverhead-len(UnreliableDatagramm)-crypto.PayloadFrameOverhead, 0)
+}
+
+// Legt den Handler für empfangene Datagramme fest, nil entfernt den Handler und empfangene Datagramme werden verworfen
+func (o *Node) SetDatagramHandler(handler DatagramHandler) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.datagramHandler = handler
+}
+
+func (o *Node) _GetDatagramHandler() DatagramHandler {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.datagramHandler
+}
+
+// Übergibt ein empfangenes Datagramm an den Handler
+func (o *Node) _DeliverDatagram(source *crypto.OpenKeyP2PAddress, payload []byte) {
+	handler := o._GetDatagramHandler()
+	if handler == nil {
+		logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Datagramm from %s dropped, no handler registered", source.ToString())
+		return
+	}
+	handler(source, payload)
+}
+
+// Baut ein verschlüsseltes Datagramm Paket für einen direkt verbundenen Peer
+func _SealDatagrammPacket(conn *NodeP2PConnection, payload []byte) ([]byte, error) {
+	frame, err := conn.payloadSession.Seal(payload, Datagramm[:])
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes.Clone(Datagramm[:]), frame...), nil
+}
+
+// Baut ein verschlüsseltes Datagramm Paket welches über Routing Kanäle an destination weitergeleitet werden kann,
+// die Weiterleitenden Nodes können den Inhalt nicht lesen
+func (o *Node) _SealRoutingChannelDatagrammPacket(destination *crypto.OpenKeyP2PAddress, payload []byte) ([]byte, error) {
+	localAddress, err := o.GetLocalNodeAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := o._GetRoutingPayloadSession(destination)
+	if err != nil {
+		return nil, err
+	}
+
+	packet := L2RoutingChannelDatagrammPacket{
+		Source:      localAddress.ToByteSlice(),
+		Destination: destination.ToByteSlice(),
+	}
+
+	packet.Frame, err = session.Seal(payload, _RoutingChannelDatagrammAAD(&packet))
+	if err != nil {
+		return nil, err
+	}
+
+	bytedPacket, err := _SerializeSteamPacket(&packet)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bytes.Clone(RoutingChannelDatagramm[:]), bytedPacket...), nil
+}
+
+// Quelle und Ziel werden mit Authentifiziert, damit ein Frame nicht umgeleitet werden kann
+func _RoutingChannelDatagrammAAD(packet *L2RoutingChannelDatagrammPacket) []byte {
+	aad := make([]byte, 0, len(RoutingChannelDatagramm)+len(packet.Source)+len(packet.Destination))
+	aad = append(aad, RoutingChannelDatagramm[:]...)
+	aad = append(aad, packet.Source...)
+	aad = append(aad, packet.Destination...)
+	return aad
+}
+
+// Entschlüsselt ein Datagramm welches direkt vom Peer der Verbindung stammt
+func _ProcessDatagrammPacket(conn *NodeP2PConnection, data []byte) error {
+	payload, err := conn.payloadSession.Open(data[2:], Datagramm[:])
+	if err != nil {
+		return fmt.Errorf("_ProcessDatagrammPacket: %w", err)
+	}
+
+	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Datagramm recived, %d bytes %s -> %s", len(payload), conn.localSocketAddress, conn.remoteSocketAddress)
+
+	// Die Identität des Absenders wurde beim Aufbau der Verbindung geprüft
+	conn.node._DeliverDatagram(conn.GetRemoteAddress(), payload)
+	return nil
+}
+
+// Entschlüsselt ein unzuverlässiges Datagramm welches direkt vom Peer der Verbindung stammt
+func _ProcessUnreliableDatagrammPacket(conn *NodeP2PConnection, data []byte) error {
+	payload, err := conn.unreliablePayloadSession.Open(data[2:], UnreliableDatagramm[:])
+	if err != nil {
+		return fmt.Errorf("_ProcessUnreliableDatagrammPacket: %w", err)
+	}
+
+	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Unreliable datagramm recived, %d bytes %s -> %s", len(payload), conn.localSocketAddress, conn.remoteSocketAddress)
+
+	// Die Identität des Absenders wurde beim Aufbau der Verbindung geprüft
+	conn.node._DeliverDatagram(conn.GetRemoteAddress(), payload)
+	return nil
+}
+
+// Entschlüsselt ein Datagramm welches über einen Routing Kanal eingetroffen ist
+func _ProcessRoutingChannelDatagrammPacket(conn *NodeP2PConnection, data []byte) error {
+	packet, err := _DeserializeRoutingChannelDatagrammPacket(data[2:])
+	if err != nil {
+		return fmt.Errorf("_ProcessRoutingChannelDatagrammPacket: %w", err)
+	}
+
+	// Es wird geprüft ob das Paket für diesen Node bestimmt ist
+	localAddress, err := conn.node.GetLocalNodeAddress()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(packet.Destination, localAddress.ToByteSlice()) {
+		logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Routing channel datagramm for another node dropped, no route available %s -> %s", conn.localSocketAddress, conn.remoteSocketAddress)
+		return nil
+	}
+
+	// Der Frame wird mit der Sitzung des Absenders entschlüsselt
+	sourceAddress, err := crypto.OpenKeyP2PAddressDecodeFromByteSlice(packet.Source)
+	if err != nil {
+		return fmt.Errorf("_ProcessRoutingChannelDatagrammPacket: %w", err)
+	}
+	// Widerrufene Absender dürfen auch über Routing Kanäle keine Datagramme an Anwendungen zustellen
+	if conn.node.IsAddressRevoked(sourceAddress) {
+		return fmt.Errorf("_ProcessRoutingChannelDatagrammPacket: %w: %s", crypto.ErrAddressRevoked, sourceAddress.ToString())
+	}
+	payload, err := conn.node._OpenRoutingPayloadFrame(sourceAddress, packet.Frame, _RoutingChannelDatagrammAAD(&packet))
+	if err != nil {
+		return fmt.Errorf("_ProcessRoutingChannelDatagrammPacket: %w", err)
+	}
+
+	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Routing channel datagramm from %s recived, %d bytes %s -> %s", sourceAddress.ToString(), len(payload), conn.localSocketAddress, conn.remoteSocketAddress)
+
+	// Die Ende-zu-Ende Sitzung ist an die Adresse des Absenders gebunden, sie ist damit geprüft
+	conn.node._DeliverDatagram(sourceAddress, payload)
+	return nil
+}
@@ -2,11 +2,13 @@ package p2p
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/quic-go/quic-go"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
 )
 
-func _TryOpenP2PConnectionTrafficStream(isIncommingConnection bool, conn quic.Connection, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtx context.Context, connCtxCancel context.CancelCauseFunc) (*NodeP2PTrafficStream, error) {
+func (o *Node) _TryOpenP2PConnectionTrafficStream(isIncommingConnection bool, conn transport.Conn, destPeerAddress *crypto.OpenKeyP2PAddress, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtx context.Context, connCtxCancel context.CancelCauseFunc) (*NodeP2PTrafficStream, error) {
 	// Es wird ein Zufälliger Wert erzeugt
 	randomValue, err := _BuildRandomVIdValue()
 	if err != nil {
@@ -14,7 +16,7 @@ func _TryOpenP2PConnectionTrafficStream(isIncommingConnection bool, conn quic.Co
 	}
 
 	// Das Paket wird Signiert und zurückgegeben
-	signature, err := _SignByteSlice(randomValue)
+	signature, err := o._SignByteSlice(randomValue)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +40,7 @@ func _TryOpenP2PConnectionTrafficStream(isIncommingConnection bool, conn quic.Co
 	}
 
 	// Der Stream wird zu einem TrafficStream Geupgradet
-	TrafficStream, err := _TypeTrafficStreamFromBidirectionalStream(streamConn)
+	TrafficStream, err := _TypeTrafficStreamFromBidirectionalStream(streamConn, destPeerAddress, o.GetRevocationStore())
 	if err != nil {
 		return nil, err
 	}
@@ -46,12 +48,21 @@ func _TryOpenP2PConnectionTrafficStream(isIncommingConnection bool, conn quic.Co
 	return TrafficStream, nil
 }
 
-func _TypeTrafficStreamFromBidirectionalStream(bidstr *QuicBidirectionalStream) (*NodeP2PTrafficStream, error) {
+func _TypeTrafficStreamFromBidirectionalStream(bidstr *QuicBidirectionalStream, destPeerAddress *crypto.OpenKeyP2PAddress, revocations *crypto.RevocationStore) (*NodeP2PTrafficStream, error) {
 	// Es wird versucht die Hello Stream Nachricht einzulesen
-	_, err := _DeserializeTrafficSteamPacket(bidstr._recivedHelloBytePacket)
+	helloStreamMessage, err := _DeserializeTrafficSteamPacket(bidstr._recivedHelloBytePacket)
 	if err != nil {
 		return nil, err
 	}
 
+	// Die Signatur muss vom selben Node stammen wie der Controlstream
+	isValid, err := _VerifyByteSliceSignature(revocations, destPeerAddress, helloStreamMessage.ValId, helloStreamMessage.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTrafficStreamSign, err)
+	}
+	if !isValid {
+		return nil, ErrInvalidTrafficStreamSign
+	}
+
 	return &NodeP2PTrafficStream{QuicBidirectionalStream: bidstr}, nil
 }
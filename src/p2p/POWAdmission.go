@@ -0,0 +1,228 @@
+package p2p
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/bits"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+)
+
+// Zulassung eingehender Verbindungen per Arbeitsnachweis. Der Listener sendet im Hello Paket eine Nonce sowie
+// die Schwierigkeit, der Verbindende muss vor dem Öffnen des Traffic Streams eine Lösung über den Control Stream
+// senden. Die Schwierigkeit setzt sich aus der Basisschwierigkeit des Listeners und einem lastabhängigen Anteil
+// zusammen, ändert sich dieser wird die neue Schwierigkeit per UpdatePOWDiff an alle eingehenden Verbindungen gesendet.
+// Der Verbindende löst höchstens seine eigene Obergrenze (SetMaxPOWSolveDifficulty), liegt die zuletzt bekannt gegebene
+// Schwierigkeit eines verbundenen Peers darüber, baut ConnectTo keine weiteren Verbindungen zu ihm auf.
+
+const (
+	powNonceSize = 32
+
+	// Größte Schwierigkeit welche ein Listener verlangt
+	powMaxAdmissionDifficulty uint8 = 24
+
+	// Zeit welche der Verbindende zum Lösen hat
+	powSolutionTimeout = 30 * time.Second
+
+	// Ab powLoadThreshold Verbindungsversuchen pro Fenster steigt die Schwierigkeit je Verdopplung um 1 Bit
+	powLoadWindow    = 10 * time.Second
+	powLoadThreshold = 16
+)
+
+// Lastmessung der eingehenden Verbindungsversuche
+type _POWLoadState struct {
+	windowStart      time.Time
+	currentAttempts  uint32
+	previousAttempts uint32
+	extraDifficulty  uint8
+}
+
+// Schließt abgelaufene Fenster ab und berechnet die Zusatzschwierigkeit anhand des aktuellen und des letzten Fensters
+func (o *_POWLoadState) _Refresh(now time.Time) (uint8, bool) {
+	if elapsed := now.Sub(o.windowStart); elapsed >= powLoadWindow {
+		if elapsed < 2*powLoadWindow {
+			o.previousAttempts = o.currentAttempts
+		} else {
+			o.previousAttempts = 0
+		}
+		o.currentAttempts = 0
+		o.windowStart = now
+	}
+
+	extra := _POWExtraDifficulty(max(o.currentAttempts, o.previousAttempts))
+	changed := extra != o.extraDifficulty
+	o.extraDifficulty = extra
+	return extra, changed
+}
+
+func _POWExtraDifficulty(attempts uint32) uint8 {
+	if attempts < powLoadThreshold {
+		return 0
+	}
+	return uint8(bits.Len32(attempts / powLoadThreshold))
+}
+
+// Gibt die aktuell gültige Schwierigkeit für einen Listener zurück
+func (o *Node) _POWEffectiveDifficulty(baseDifficulty uint8) uint8 {
+	return uint8(min(uint(baseDifficulty)+uint(o._GetPOWLoadDifficulty()), uint(powMaxAdmissionDifficulty)))
+}
+
+// Erzeugt die Challenge für eine eingehende Verbindung, ist keine Arbeit erforderlich wird nil zurückgegeben
+func (o *Node) _NewPOWChallenge(baseDifficulty uint8) (*L1POWChallenge, error) {
+	difficulty := o._POWEffectiveDifficulty(baseDifficulty)
+	if difficulty == 0 {
+		return nil, nil
+	}
+
+	nonce := make([]byte, powNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &L1POWChallenge{Nonce: nonce, Difficulty: difficulty}, nil
+}
+
+// Der Input bindet die Lösung an die Nonce des Listeners sowie an den Signer Key des Verbindenden
+func _POWChallengeInput(challenge *L1POWChallenge, signerKeyType openkeyp2p.OpenKeyP2PKeyType, signerKey NodePublicSignatureKey) []byte {
+	reval := make([]byte, 0, len(challenge.Nonce)+1+len(signerKey))
+	reval = append(reval, challenge.Nonce...)
+	reval = append(reval, byte(signerKeyType))
+	return append(reval, signerKey...)
+}
+
+// Legt die größte Schwierigkeit fest welche der Node beim Verbinden löst, 0 löst keine Challenges.
+// Werte oberhalb der Schwierigkeit welche ein Node selbst höchstens verlangt werden auf diese begrenzt
+func (o *Node) SetMaxPOWSolveDifficulty(difficulty uint8) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.maxPOWSolveDifficulty = min(difficulty, powMaxAdmissionDifficulty)
+}
+
+// Gibt die größte Schwierigkeit zurück welche der Node beim Verbinden löst
+func (o *Node) GetMaxPOWSolveDifficulty() uint8 {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.maxPOWSolveDifficulty
+}
+
+// Prüft vor dem Verbindungsaufbau die zuletzt bekannt gegebene Schwierigkeit bestehender ausgehender Verbindungen
+// zum selben Peer bzw. zur selben Socket Adresse, liegt sie über der eigenen Obergrenze wird nicht verbunden
+func (o *Node) _CheckAnnouncedPOWDifficulty(peer *crypto.OpenKeyP2PAddress, remoteSocketAddress NodeP2PSocketAddress) error {
+	var resolvedPeer *crypto.OpenKeyP2PAddress
+	if peer != nil {
+		resolvedPeer = o.ResolveSuccessorAddress(peer)
+	}
+
+	maxDifficulty := o.GetMaxPOWSolveDifficulty()
+	for _, conn := range o._GetConnections() {
+		if conn.isIncommingConnection || conn.GetRemotePOWDifficulty() <= maxDifficulty {
+			continue
+		}
+		if conn.remoteSocketAddress == remoteSocketAddress || (resolvedPeer != nil && o.ResolveSuccessorAddress(conn.GetRemoteAddress()).Equal(resolvedPeer)) {
+			return fmt.Errorf("%w: peer %s requires %d, the limit is %d", ErrInvalidPOWDifficulty, conn.remoteSocketAddress, conn.GetRemotePOWDifficulty(), maxDifficulty)
+		}
+	}
+	return nil
+}
+
+// Löst die Challenge des Listeners und sendet die Lösung über den Control Stream. Challenges oberhalb der
+// eigenen Obergrenze werden nicht gelöst
+func (o *Node) _SendPOWSolution(ctx context.Context, controlStream *NodeP2PControlStream) error {
+	challenge := controlStream.destPeerHelloPacket.POWChallenge
+	if len(challenge.Nonce) != powNonceSize {
+		return fmt.Errorf("%w: invalid nonce size %d", ErrInvalidPOWDifficulty, len(challenge.Nonce))
+	}
+	if maxDifficulty := o.GetMaxPOWSolveDifficulty(); challenge.Difficulty > maxDifficulty {
+		return fmt.Errorf("%w: %d exceeds the maximum of %d", ErrInvalidPOWDifficulty, challenge.Difficulty, maxDifficulty)
+	}
+
+	solveCtx, cancel := context.WithTimeout(ctx, powSolutionTimeout)
+	defer cancel()
+
+	input := _POWChallengeInput(challenge, o._GetSignerKeyType(), o._GetSignerPublicKey())
+	solution, err := crypto.SolveProofOfWork(solveCtx, input, challenge.Difficulty)
+	if err != nil {
+		return fmt.Errorf("solving proof of work: %w", err)
+	}
+
+	return controlStream.WriteBytes(append([]byte(POWSolution[:]), openkeyp2p.Uint64ToBytesLE(solution)...))
+}
+
+// Wartet auf die Lösung des Verbindenden und prüft sie, ohne gültige Lösung wird die Verbindung abgelehnt
+func _ReceivePOWSolution(controlStream *NodeP2PControlStream, challenge *L1POWChallenge) error {
+	if err := controlStream.inStream.SetReadDeadline(time.Now().Add(powSolutionTimeout)); err != nil {
+		return err
+	}
+	defer controlStream.inStream.SetReadDeadline(time.Time{})
+
+	data, err := controlStream.ReadBytes()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPOWSolution, err)
+	}
+	if len(data) != 2+8 || [2]byte(data[:2]) != POWSolution {
+		return fmt.Errorf("%w: unexpected packet", ErrInvalidPOWSolution)
+	}
+
+	input := _POWChallengeInput(challenge, controlStream.destPeerHelloPacket.SignerKeyType, controlStream.destPeerHelloPacket.SignerKey)
+	if !crypto.VerifyProofOfWork(input, challenge.Difficulty, openkeyp2p.BytesToUint64LE(data[2:])) {
+		return ErrInvalidPOWSolution
+	}
+	return nil
+}
+
+// Zählt einen eingehenden Verbindungsversuch, steigt dadurch die Schwierigkeit wird sie sofort bekannt gegeben
+func (o *Node) _RecordIncomingPOWAttempt() {
+	if _, changed := o._RecordPOWAttempt(); changed {
+		o._AnnouncePOWDifficulty()
+	}
+}
+
+// Sendet die aktuelle Schwierigkeit an alle über einen Listener verbundenen Peers
+func (o *Node) _AnnouncePOWDifficulty() {
+	for _, conn := range o._GetIncomingConnections() {
+		difficulty := o._POWEffectiveDifficulty(conn.powBaseDifficulty)
+		if err := conn.writerControlBuffer.Put(append([]byte(UpdatePOWDiff[:]), difficulty)); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by announcing pow difficulty {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
+	}
+}
+
+// Schließt regelmäßig das Lastfenster ab, damit die Schwierigkeit auch ohne neue Verbindungsversuche sinkt,
+// die Routine endet sobald ctx beendet wird
+func (o *Node) _POWLoadRoutine(ctx context.Context) {
+	ticker := time.NewTicker(powLoadWindow)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if extra, changed := o._RefreshPOWLoad(); changed {
+				logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Proof of work load difficulty changed to +%d", extra)
+				o._AnnouncePOWDifficulty()
+			}
+		}
+	}
+}
+
+// Übernimmt die von der Gegenseite bekannt gegebene Schwierigkeit, sie gilt für weitere Verbindungen zu diesem Peer
+func _ProcessUpdatePOWDiffPacket(conn *NodeP2PConnection, data []byte) error {
+	if len(data) != 3 {
+		return fmt.Errorf("%w: invalid packet size %d", ErrInvalidPOWDifficulty, len(data))
+	}
+	if data[2] > powMaxAdmissionDifficulty {
+		return fmt.Errorf("%w: %d", ErrInvalidPOWDifficulty, data[2])
+	}
+
+	conn.remotePOWDifficulty.Store(uint32(data[2]))
+	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Peer pow difficulty updated to %d %s -> %s", data[2], conn.localSocketAddress, conn.remoteSocketAddress)
+	return nil
+}
+
+// Gibt die zuletzt von der Gegenseite verlangte Schwierigkeit für neue Verbindungen zurück
+func (o *NodeP2PConnection) GetRemotePOWDifficulty() uint8 {
+	return uint8(o.remotePOWDifficulty.Load())
+}
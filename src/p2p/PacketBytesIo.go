@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"github.com/fxamacker/cbor/v2"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
 )
 
 // Serialize serialisiert die Struktur in CBOR
@@ -9,6 +10,11 @@ func _SerializeSteamPacket(packet interface{}) ([]byte, error) {
 	return cbor.Marshal(packet)
 }
 
+// Serialisiert die Struktur in kanonisches CBOR, die Ausgabe ist für gleiche Werte immer identisch
+func _SerializeCanonicalSteamPacket(packet interface{}) ([]byte, error) {
+	return crypto.CanonicalEncMode().Marshal(packet)
+}
+
 // Deserialize deserialisiert CBOR-Daten zurück in die Struktur
 func _DeserializeHelloControlSteamPacket(data []byte) (L1HelloControlSteamPacket, error) {
 	var packet L1HelloControlSteamPacket
@@ -16,6 +22,27 @@ func _DeserializeHelloControlSteamPacket(data []byte) (L1HelloControlSteamPacket
 	return packet, err
 }
 
+// Deserialize deserialisiert CBOR-Daten zurück in die Struktur
+func _DeserializeRoutingChannelDatagrammPacket(data []byte) (L2RoutingChannelDatagrammPacket, error) {
+	var packet L2RoutingChannelDatagrammPacket
+	err := cbor.Unmarshal(data, &packet)
+	return packet, err
+}
+
+// Deserialize deserialisiert CBOR-Daten zurück in die Struktur
+func _DeserializeStreamProtocolSelectPacket(data []byte) (L1StreamProtocolSelectPacket, error) {
+	var packet L1StreamProtocolSelectPacket
+	err := cbor.Unmarshal(data, &packet)
+	return packet, err
+}
+
+// Deserialize deserialisiert CBOR-Daten zurück in die Struktur
+func _DeserializeStreamProtocolSelectReplyPacket(data []byte) (L1StreamProtocolSelectReplyPacket, error) {
+	var packet L1StreamProtocolSelectReplyPacket
+	err := cbor.Unmarshal(data, &packet)
+	return packet, err
+}
+
 // Deserialize deserialisiert CBOR-Daten zurück in die Struktur
 func _DeserializeTrafficSteamPacket(data []byte) (L1HelloTrafficStreamPacket, error) {
 	var packet L1HelloTrafficStreamPacket
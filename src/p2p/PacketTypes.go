@@ -13,6 +13,11 @@ var (
 	UpdatePOWDiff                     NodeP2PPacketHeader = NodeP2PPacketHeader{0, 6}
 	UpdateAutoRoutingQuickSearchTable NodeP2PPacketHeader = NodeP2PPacketHeader{0, 7}
 	PeerDiscovery                     NodeP2PPacketHeader = NodeP2PPacketHeader{0, 8}
+	IdentitySuccession                NodeP2PPacketHeader = NodeP2PPacketHeader{0, 9}
+	POWSolution                       NodeP2PPacketHeader = NodeP2PPacketHeader{0, 10}
+	Revocation                        NodeP2PPacketHeader = NodeP2PPacketHeader{0, 11}
+	Goodbye                           NodeP2PPacketHeader = NodeP2PPacketHeader{0, 12}
+	UnreliableDatagramm               NodeP2PPacketHeader = NodeP2PPacketHeader{0, 13}
 )
 
 type L1HelloControlSteamPacketWSig struct {
@@ -27,6 +32,16 @@ type L1HelloControlSteamPacketWSig struct {
 	CMTU               uint16                        `cbor:"10"`
 	ACKPerPackage      bool                          `cbor:"11"`
 	MaxPacketPerSecond uint16                        `cbor:"12"`
+	SignerKeyType      openkeyp2p.OpenKeyP2PKeyType  `cbor:"13,omitempty"`
+	SuccessionRecords  [][]byte                      `cbor:"14,omitempty"`
+	POWChallenge       *L1POWChallenge               `cbor:"15,omitempty"`
+	DatagramSupport    bool                          `cbor:"16,omitempty"`
+}
+
+// Arbeitsnachweis welchen der Listener vom Verbindenden verlangt, die Lösung ist an die Nonce sowie an den Signer Key des Verbindenden gebunden
+type L1POWChallenge struct {
+	Nonce      []byte `cbor:"1"`
+	Difficulty uint8  `cbor:"2"`
 }
 
 type L1HelloControlSteamPacket struct {
@@ -39,5 +54,23 @@ type L1HelloTrafficStreamPacket struct {
 	Signature []byte                        `cbor:"1"`
 }
 
+// Erstes Paket eines Anwendungs Streams, der Öffnende wählt das Protokoll aus
+type L1StreamProtocolSelectPacket struct {
+	ProtocolId StreamProtocolId `cbor:"1"`
+}
+
+// Antwort auf die Protokollauswahl, Accepted ist false sofern die Gegenseite keinen Handler für das Protokoll besitzt
+type L1StreamProtocolSelectReplyPacket struct {
+	ProtocolId StreamProtocolId `cbor:"1"`
+	Accepted   bool             `cbor:"2"`
+}
+
 type L2KeepaliveTransportPacket struct {
 }
+
+// Wird über Routing Kanäle weitergeleitet, Frame ist mit der Sitzung zwischen Source und Destination verschlüsselt
+type L2RoutingChannelDatagrammPacket struct {
+	Source      []byte `cbor:"1"`
+	Destination []byte `cbor:"2"`
+	Frame       []byte `cbor:"3"`
+}
@@ -5,15 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"sync"
 
 	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
 	"github.com/ms2sh/OpenKeyP2P/src/logging"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
 	"github.com/quic-go/quic-go"
 )
 
-func _StreamWriteBytePacket(stream quic.Stream, data []byte, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtxCancel context.CancelCauseFunc) error {
+func _StreamWriteBytePacket(stream transport.Stream, data []byte, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtxCancel context.CancelCauseFunc) error {
 	// Der Header, bestehend aus der Datenlänge wird hinzugefügt
 	dataLength := len(data)
 	dataLengthBytes := openkeyp2p.Uint64ToBytesLE(uint64(dataLength))
@@ -25,6 +27,7 @@ func _StreamWriteBytePacket(stream quic.Stream, data []byte, localSocketEp NodeP
 			netErr    net.Error
 			streamErr *quic.StreamError
 			connErr   *quic.ApplicationError
+			appErr    *transport.ApplicationError
 		)
 
 		switch {
@@ -36,6 +39,8 @@ func _StreamWriteBytePacket(stream quic.Stream, data []byte, localSocketEp NodeP
 			return fmt.Errorf("QUIC stream error (code %d): %w", streamErr.ErrorCode, err)
 		case errors.As(err, &connErr):
 			return fmt.Errorf("QUIC connection error (code %d): %w", connErr.ErrorCode, err)
+		case errors.As(err, &appErr):
+			return fmt.Errorf("connection error (code %d): %w", appErr.Code, err)
 		default:
 			return fmt.Errorf("write failed: %w", err)
 		}
@@ -47,7 +52,12 @@ func _StreamWriteBytePacket(stream quic.Stream, data []byte, localSocketEp NodeP
 	return nil
 }
 
-func _StreamReadBytePacket(stream quic.Stream, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtxCancel context.CancelCauseFunc) ([]byte, error) {
+func _StreamReadBytePacket(stream transport.Stream, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtxCancel context.CancelCauseFunc) ([]byte, error) {
+	return _StreamReadLimitedBytePacket(stream, math.MaxUint64, localSocketEp, remoteSocketEp)
+}
+
+// Liest ein Paket ein, ist es größer als maxSize wird es nicht eingelesen und ein Fehler zurückgegeben
+func _StreamReadLimitedBytePacket(stream transport.Stream, maxSize uint64, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress) ([]byte, error) {
 	// Die Länge des Datensatzes wird ausgelesen
 	dataLengthBytes := make([]byte, 8)
 	n, err := io.ReadFull(stream, dataLengthBytes)
@@ -65,6 +75,9 @@ func _StreamReadBytePacket(stream quic.Stream, localSocketEp NodeP2PSocketAddres
 		return nil, fmt.Errorf("invalid data")
 	}
 	dataLength := openkeyp2p.BytesToUint64LE(dataLengthBytes)
+	if dataLength > maxSize {
+		return nil, fmt.Errorf("packet too large (%d bytes, maximum %d bytes)", dataLength, maxSize)
+	}
 
 	// Der Restliche Datensatz wird ausgelesen
 	dataBytes := make([]byte, dataLength)
@@ -79,10 +92,10 @@ func _StreamReadBytePacket(stream quic.Stream, localSocketEp NodeP2PSocketAddres
 	return dataBytes, nil
 }
 
-func _TryOpenQuicBidirectionalStream(isIncommingConnection bool, conn quic.Connection, helloPackage []byte, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtx context.Context, connCtxCancel context.CancelCauseFunc) (*QuicBidirectionalStream, error) {
+func _TryOpenQuicBidirectionalStream(isIncommingConnection bool, conn transport.Conn, helloPackage []byte, localSocketEp NodeP2PSocketAddress, remoteSocketEp NodeP2PSocketAddress, connCtx context.Context, connCtxCancel context.CancelCauseFunc) (*QuicBidirectionalStream, error) {
 	// Es wird selektiert, ob es sich um eine eingehende oder um eine ausgehende Verbindung handelt
-	var inStream quic.Stream
-	var outStream quic.Stream
+	var inStream transport.Stream
+	var outStream transport.Stream
 	var streamErr error
 	var recivedPacket []byte
 	if isIncommingConnection {
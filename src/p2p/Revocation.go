@@ -0,0 +1,199 @@
+package p2p
+
+import (
+	"fmt"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/logging"
+)
+
+// Zeitfenster und Anzahl der Widerrufe welche eine Verbindung darin senden darf, weitere Widerrufe werden
+// verworfen. Einer neuen Verbindung werden höchstens maxRevocationsPerWindow bekannte Widerrufe gesendet
+const (
+	revocationRateWindow    = time.Minute
+	maxRevocationsPerWindow = 64
+)
+
+// Legt den Store für Widerrufe und Widerrufs-Authorities fest, nil setzt einen neuen leeren Store.
+// Der Standard Node verwendet crypto.DefaultRevocationStore()
+func (o *Node) SetRevocationStore(store *crypto.RevocationStore) {
+	if store == nil {
+		store = crypto.NewRevocationStore()
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.revocations = store
+}
+
+// Gibt den Store für Widerrufe und Widerrufs-Authorities zurück
+func (o *Node) GetRevocationStore() *crypto.RevocationStore {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.revocations
+}
+
+// Gibt an ob die Adresse im Store des Nodes widerrufen wurde
+func (o *Node) IsAddressRevoked(address *crypto.OpenKeyP2PAddress) bool {
+	return o.GetRevocationStore().IsAddressRevoked(address)
+}
+
+// Speichert einen Widerruf und verteilt ihn über den Standard Node
+func PublishRevocation(statement *crypto.RevocationStatement) error {
+	node, err := _VarsGetDefaultNode()
+	if err != nil {
+		return err
+	}
+	return node.PublishRevocation(statement)
+}
+
+// Speichert einen Widerruf lokal und verteilt ihn an alle verbundenen Peers,
+// bestehende Verbindungen zur widerrufenen Adresse werden getrennt
+func (o *Node) PublishRevocation(statement *crypto.RevocationStatement) error {
+	if !o._IsRunning() {
+		return ErrNodeClosed
+	}
+
+	if added, err := o.GetRevocationStore().AddRevocation(statement); err != nil {
+		if !added {
+			return err
+		}
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by saving revocation {%s}", err)
+	}
+
+	statementBytes, err := statement.ToByteSlice()
+	if err != nil {
+		return err
+	}
+
+	o._GossipRevocation(statementBytes, nil)
+	o._DisconnectRevokedPeers()
+	return nil
+}
+
+// Verarbeitet einen über den Control Stream empfangenen Widerruf, neue Widerrufe werden an alle anderen Peers weitergeleitet
+func _ProcessRevocationPacket(conn *NodeP2PConnection, data []byte) error {
+	// Die Anzahl der Widerrufe je Verbindung wird begrenzt, bevor die Signatur geprüft wird
+	if !conn._AllowRevocation(time.Now()) {
+		return fmt.Errorf("%w: more than %d revocations per %s", crypto.ErrRevocationLimitReached, maxRevocationsPerWindow, revocationRateWindow)
+	}
+
+	// Die Signatur wird erst beim Speichern geprüft
+	statement, err := crypto.DecodeRevocationStatement(data[2:])
+	if err != nil {
+		return err
+	}
+	revokedAddress, err := statement.GetRevokedAddress()
+	if err != nil {
+		return err
+	}
+
+	if !conn.node._IsRevocationRelevant(statement) {
+		logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Self revocation of unknown address %s ignored %s -> %s", revokedAddress.ToString(), conn.localSocketAddress, conn.remoteSocketAddress)
+		return nil
+	}
+
+	added, err := conn.node.GetRevocationStore().AddRevocation(statement)
+	if !added {
+		return err
+	}
+	if err != nil {
+		logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by saving revocation {%s}", err)
+	}
+
+	logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Revocation of %s accepted %s -> %s", revokedAddress.ToString(), conn.localSocketAddress, conn.remoteSocketAddress)
+
+	conn.node._GossipRevocation(data[2:], conn)
+	conn.node._DisconnectRevokedPeers()
+	return nil
+}
+
+// Zählt einen empfangenen Widerruf, wird nur von der Control Stream Reader Routine der Verbindung aufgerufen
+func (o *NodeP2PConnection) _AllowRevocation(now time.Time) bool {
+	if now.Sub(o.revocationWindowStart) >= revocationRateWindow {
+		o.revocationWindowStart = now
+		o.revocationCount = 0
+	}
+	if o.revocationCount >= maxRevocationsPerWindow {
+		return false
+	}
+	o.revocationCount++
+	return true
+}
+
+// Ein Selbstwiderruf kann mit frisch erzeugten Schlüsseln von jedem kostenlos erstellt werden, er wird daher
+// nur gespeichert und weitergeleitet wenn die Adresse im Adressbuch steht oder eine Verbindung zu ihr besteht.
+// Widerrufe eines Authority Schlüssels werden immer übernommen
+func (o *Node) _IsRevocationRelevant(statement *crypto.RevocationStatement) bool {
+	revokedAddress, err := statement.GetRevokedAddress()
+	if err != nil {
+		return false
+	}
+	revokerAddress, err := statement.GetRevokerAddress()
+	if err != nil {
+		return false
+	}
+	if !revokedAddress.Equal(revokerAddress) {
+		return true
+	}
+	return o._IsKnownPeerAddress(revokedAddress)
+}
+
+// Gibt an ob die Adresse im Adressbuch steht oder eine Verbindung zu ihr besteht
+func (o *Node) _IsKnownPeerAddress(address *crypto.OpenKeyP2PAddress) bool {
+	if book := o.GetAddressBook(); book != nil {
+		if _, found := book.Get(address); found {
+			return true
+		}
+	}
+	for _, conn := range o._GetConnections() {
+		if conn.controlStream.GetDestinationAddress().Equal(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sendet einen Widerruf an alle Verbindungen außer except
+func (o *Node) _GossipRevocation(statementBytes []byte, except *NodeP2PConnection) {
+	packet := append([]byte(Revocation[:]), statementBytes...)
+	for _, conn := range o._GetConnections() {
+		if conn == except {
+			continue
+		}
+		if err := conn.writerControlBuffer.Put(packet); err != nil {
+			logging.LogError(openkeyp2p.LOG_LEVEL_P2P, "Error by sending revocation {%s} %s -> %s", err, conn.localSocketAddress, conn.remoteSocketAddress)
+		}
+	}
+}
+
+// Sendet die neuesten lokal bekannten Widerrufe an eine neue Verbindung, die Gegenseite nimmt je
+// Zeitfenster nicht mehr als maxRevocationsPerWindow an
+func _QueueKnownRevocations(conn *NodeP2PConnection) error {
+	statements := conn.node.GetRevocationStore().GetRevocations()
+	if len(statements) > maxRevocationsPerWindow {
+		statements = statements[:maxRevocationsPerWindow]
+	}
+	for _, statement := range statements {
+		statementBytes, err := statement.ToByteSlice()
+		if err != nil {
+			return err
+		}
+		if err := conn.writerControlBuffer.Put(append([]byte(Revocation[:]), statementBytes...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Trennt alle Verbindungen deren Gegenseite widerrufen wurde
+func (o *Node) _DisconnectRevokedPeers() {
+	for _, conn := range o._GetConnections() {
+		peerAddress := conn.controlStream.GetDestinationAddress()
+		if o.IsAddressRevoked(peerAddress) {
+			logging.LogInfo(openkeyp2p.LOG_LEVEL_P2P, "Connection to revoked peer %s closed %s -> %s", peerAddress.ToString(), conn.localSocketAddress, conn.remoteSocketAddress)
+			conn.contextCancel(fmt.Errorf("%w: %s", crypto.ErrAddressRevoked, peerAddress.ToString()))
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package p2p
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+)
+
+// Maximale Anzahl an Ende-zu-Ende Sitzungen mit über Routing Kanäle erreichbaren Adressen, darüber hinaus
+// wird die am längsten nicht verwendete Sitzung verworfen. Ihre Replay Einträge bleiben erhalten bis alle
+// Frames vor der Verdrängung veraltet sind und werden von einer neuen Sitzung zur selben Adresse übernommen
+const maxRoutingPayloadSessions = 1024
+
+// Maximale Anzahl an Adressen mit Replay Einträgen verdrängter Sitzungen, ist die Grenze erreicht werden
+// keine weiteren Sitzungen verdrängt und neue Adressen abgelehnt bis die ältesten Einträge veraltet sind
+const maxRoutingReplayStates = 4 * maxRoutingPayloadSessions
+
+type _RoutingSessionEntry struct {
+	key     string
+	session *crypto.PayloadSession
+}
+
+// LRU Speicher der Routing Sitzungen eines Nodes
+type _RoutingSessionCache struct {
+	lock         *sync.Mutex
+	entries      map[string]*list.Element
+	order        *list.List
+	replayStates map[string]*crypto.PayloadReplayState
+}
+
+func _NewRoutingSessionCache() *_RoutingSessionCache {
+	return &_RoutingSessionCache{
+		lock:         new(sync.Mutex),
+		entries:      make(map[string]*list.Element),
+		order:        list.New(),
+		replayStates: make(map[string]*crypto.PayloadReplayState),
+	}
+}
+
+// Gibt die Sitzung zurück und markiert sie als zuletzt verwendet
+func (o *_RoutingSessionCache) _Load(key string) (*crypto.PayloadSession, bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	element, found := o.entries[key]
+	if !found {
+		return nil, false
+	}
+	o.order.MoveToFront(element)
+	return element.Value.(*_RoutingSessionEntry).session, true
+}
+
+// Übergibt einer neuen Sitzung die Replay Einträge einer zuvor verdrängten Sitzung derselben Adresse,
+// muss vor dem ersten Open der Sitzung aufgerufen werden
+func (o *_RoutingSessionCache) _RestoreReplayState(key string, session *crypto.PayloadSession) {
+	o.lock.Lock()
+	state, found := o.replayStates[key]
+	o.lock.Unlock()
+	if found {
+		session.ImportReplayState(state)
+	}
+}
+
+// Speichert die Sitzung sofern noch keine existiert und gibt die gespeicherte Sitzung zurück
+func (o *_RoutingSessionCache) _LoadOrStore(key string, session *crypto.PayloadSession) (*crypto.PayloadSession, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if element, found := o.entries[key]; found {
+		o.order.MoveToFront(element)
+		return element.Value.(*_RoutingSessionEntry).session, nil
+	}
+
+	// Ohne Platz für die Replay Einträge der zu verdrängenden Sitzung wird die neue Sitzung abgelehnt
+	if o.order.Len() >= maxRoutingPayloadSessions {
+		o._PruneReplayStatesLocked(time.Now())
+		if len(o.replayStates) >= maxRoutingReplayStates {
+			return nil, ErrRoutingSessionLimitReached
+		}
+	}
+
+	delete(o.replayStates, key)
+	o.entries[key] = o.order.PushFront(&_RoutingSessionEntry{key: key, session: session})
+	for o.order.Len() > maxRoutingPayloadSessions {
+		oldest := o.order.Back()
+		o.order.Remove(oldest)
+		evicted := oldest.Value.(*_RoutingSessionEntry)
+		delete(o.entries, evicted.key)
+		o.replayStates[evicted.key] = evicted.session.ExportReplayState()
+	}
+	return session, nil
+}
+
+// Entfernt die Replay Einträge deren Frames inzwischen veraltet sind
+func (o *_RoutingSessionCache) _PruneReplayStatesLocked(now time.Time) {
+	for key, state := range o.replayStates {
+		if state.IsExpired(now) {
+			delete(o.replayStates, key)
+		}
+	}
+}
+
+// Verwirft die Sitzung, wird nur für Adressen verwendet welche danach nicht mehr angenommen werden
+func (o *_RoutingSessionCache) _Delete(key string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if element, found := o.entries[key]; found {
+		o.order.Remove(element)
+		delete(o.entries, key)
+	}
+}
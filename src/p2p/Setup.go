@@ -1,18 +1,27 @@
 package p2p
 
-import "fmt"
+import (
+	"fmt"
 
-func Setup() error {
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+)
+
+// Erzeugt den Standard Node welcher von den Paketfunktionen verwendet wird
+func Setup(signer crypto.OpenKeyP2PSigner) error {
 	controlLock.Lock()
 	defer controlLock.Unlock()
 
-	if wasSetuped {
+	if defaultNode != nil {
 		return fmt.Errorf("was always setup")
 	}
 
-	nodeConnections = make(map[ConnectionId]*NodeP2PConnection)
-
-	wasSetuped = true
+	node, err := NewNode(signer)
+	if err != nil {
+		return err
+	}
+	node.SetAddressBook(defaultAddressBook)
+	node.SetRevocationStore(crypto.DefaultRevocationStore())
 
+	defaultNode = node
 	return nil
 }
@@ -1,5 +1,34 @@
 package p2p
 
-import "errors"
+import (
+	"errors"
 
-var ErrTimeout = errors.New("operation timed out")
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
+)
+
+const (
+	ErrorCodeHandshakeFailed transport.ApplicationErrorCode = 1
+	ErrorCodeDisconnected    transport.ApplicationErrorCode = 2
+)
+
+var (
+	ErrTimeout                    = errors.New("operation timed out")
+	ErrInvalidSignerKey           = errors.New("invalid signer key")
+	ErrInvalidHelloSignature      = errors.New("invalid hello packet signature")
+	ErrInvalidTrafficStreamSign   = errors.New("invalid traffic stream signature")
+	ErrSuccessionConflict         = errors.New("address already has a different successor")
+	ErrSuccessionLimitReached     = errors.New("identity succession limit reached")
+	ErrInvalidPOWSolution         = errors.New("invalid proof of work solution")
+	ErrInvalidPOWDifficulty       = errors.New("invalid proof of work difficulty")
+	ErrConnectionNotFound         = errors.New("connection not found")
+	ErrConnectionDisconnected     = errors.New("connection disconnected")
+	ErrNodeClosed                 = errors.New("p2p node is closed")
+	ErrNodeNotSetup               = errors.New("you must setup p2p node functions, call Setup()")
+	ErrPeerGoodbye                = errors.New("peer said goodbye")
+	ErrPeerNotConnected           = errors.New("no connection to peer")
+	ErrDatagramTooLarge           = errors.New("datagram payload too large")
+	ErrNoUnreliableDatagrams      = errors.New("unreliable datagrams not negotiated with peer")
+	ErrInvalidProtocolId          = errors.New("invalid stream protocol id")
+	ErrProtocolNotSupported       = errors.New("stream protocol not supported by peer")
+	ErrRoutingSessionLimitReached = errors.New("routing session limit reached")
+)
@@ -1,17 +1,19 @@
 package p2p
 
 import (
-	"errors"
 	"fmt"
 	"net"
 	"regexp"
 
-	"github.com/quic-go/quic-go"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
 )
 
-// Gibt die Lokale IP Adresse einer Quic Verbindung aus
-func getLocalIPFromConn(conn quic.Connection) string {
-	addr := conn.LocalAddr().(*net.UDPAddr)
+// Gibt die Lokale IP Adresse einer Verbindung aus, für Adressen ohne IP wird die Adresse selbst ausgegeben
+func getLocalIPFromConn(conn transport.Conn) string {
+	addr, isUDP := conn.LocalAddr().(*net.UDPAddr)
+	if !isUDP {
+		return conn.LocalAddr().String()
+	}
 	if addr.IP.IsUnspecified() {
 		ips, err := net.InterfaceAddrs()
 		if err == nil {
@@ -29,8 +31,11 @@ func getLocalIPFromConn(conn quic.Connection) string {
 }
 
 // getRemoteIPAndHostFromConn gibt die Remote-IP-Adresse, den Port sowie den Hostnamen zurück.
-func getRemoteIPAndHostFromConn(conn quic.Connection) string {
-	addr := conn.RemoteAddr().(*net.UDPAddr)
+func getRemoteIPAndHostFromConn(conn transport.Conn) string {
+	addr, isUDP := conn.RemoteAddr().(*net.UDPAddr)
+	if !isUDP {
+		return conn.RemoteAddr().String()
+	}
 	hostname, _ := getHostnameFromIP(addr.IP.String())
 	return fmt.Sprintf("%s:%d (%s)", addr.IP.String(), addr.Port, hostname)
 }
@@ -48,46 +53,6 @@ func getHostnameFromIP(ip string) (string, error) {
 	return ip, nil // Wenn kein Hostname gefunden wird, zurückgeben von ""
 }
 
-// GetInterfaceByIP ermittelt das Netzwerkinterface anhand einer gegebenen IP-Adresse
-func getInterfaceByIP(ipAddress string) (*net.Interface, error) {
-	ip := net.ParseIP(ipAddress)
-	if ip == nil {
-		return nil, errors.New("ungültige IP-Adresse")
-	}
-
-	interfaces, err := net.Interfaces()
-	if err != nil {
-		return nil, err
-	}
-
-	for _, iface := range interfaces {
-		addrs, err := iface.Addrs()
-		if err != nil {
-			continue
-		}
-
-		// Debugging: Alle Adressen des Interfaces ausgeben
-		for _, addr := range addrs {
-			switch v := addr.(type) {
-			case *net.IPNet:
-				// IPv4 oder IPv6 Adressen
-				if v.IP.Equal(ip) {
-					return &iface, nil
-				}
-			case *net.IPAddr:
-				// IPv4 oder IPv6 Adresse
-				if v.IP.Equal(ip) {
-					return &iface, nil
-				}
-			}
-		}
-	}
-
-	// Debugging: IP-Adresse, die nicht gefunden wurde
-	fmt.Println("Local IP:", ipAddress)
-	return nil, errors.New("kein passendes Interface gefunden")
-}
-
 // Überprüft, ob der Name nur aus erlaubten Zeichen besteht: a-z, A-Z, 0-9, _ und -
 func isValidName(name string) bool {
 	// Definiere ein Regex, das nur alphanumerische Zeichen, "_" und "-" erlaubt
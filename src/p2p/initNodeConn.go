@@ -3,18 +3,25 @@ package p2p
 import (
 	"context"
 	"fmt"
-	"net"
 	"sync"
 	"time"
 
 	"slices"
 
 	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
 	"github.com/ms2sh/OpenKeyP2P/src/logging"
-	"github.com/quic-go/quic-go"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
 )
 
-func _InitQUICNodeConn(localhostNetworkInterface *net.Interface, ctx context.Context, cancel context.CancelCauseFunc, isIncommingConnection bool, config NodeP2PConnectionConfig, conn quic.Connection) (*NodeP2PConnection, error) {
+func (o *Node) _InitNodeConn(ctx context.Context, cancel context.CancelCauseFunc, isIncommingConnection bool, config NodeP2PConnectionConfig, powBaseDifficulty uint8, conn transport.Conn) (_ *NodeP2PConnection, err error) {
+	// Sollte die Initalisierung fehlschlagen, wird die Quic Verbindung geschlossen
+	defer func() {
+		if err != nil {
+			conn.CloseWithError(ErrorCodeHandshakeFailed, err.Error())
+		}
+	}()
+
 	// Der Lokale EP sowie der Remote EP wird abgerufen
 	localEndpointStr := getLocalIPFromConn(conn)
 	remoteEndpointStr := getRemoteIPAndHostFromConn(conn)
@@ -22,12 +29,55 @@ func _InitQUICNodeConn(localhostNetworkInterface *net.Interface, ctx context.Con
 	// LOG
 	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "An attempt is made to initialize the connection %s -> %s", localEndpointStr, remoteEndpointStr)
 
+	// Eingehende Verbindungen erhalten eine Challenge für den Arbeitsnachweis, sofern die aktuelle Schwierigkeit dies verlangt
+	var powChallenge *L1POWChallenge
+	if isIncommingConnection {
+		if powChallenge, err = o._NewPOWChallenge(powBaseDifficulty); err != nil {
+			return nil, err
+		}
+	}
+
 	// Die Control Streams werden geöffnet
-	controlStream, err := _TryOpenP2PConnectionControlStream(localhostNetworkInterface, isIncommingConnection, conn, config, NodeP2PSocketAddress(localEndpointStr), NodeP2PSocketAddress(remoteEndpointStr), ctx, cancel)
+	controlStream, err := o._TryOpenP2PConnectionControlStream(isIncommingConnection, conn, config, powChallenge, NodeP2PSocketAddress(localEndpointStr), NodeP2PSocketAddress(remoteEndpointStr), ctx, cancel)
 	if err != nil {
 		return nil, err
 	}
 
+	// Eingehende Verbindungen müssen den Arbeitsnachweis erbringen bevor Signaturen oder Zertifikate geprüft werden,
+	// so kann ein Verbindender ohne Lösung keine teuren Prüfungen auslösen
+	if isIncommingConnection && powChallenge != nil {
+		if err := _ReceivePOWSolution(controlStream, powChallenge); err != nil {
+			return nil, err
+		}
+	}
+
+	// Die Signatur des Hello Paketes wird geprüft
+	if err := controlStream._VerifyHello(o.GetRevocationStore()); err != nil {
+		return nil, err
+	}
+
+	// Die Identität aus dem TLS Zertifikat muss mit dem Signer Key des Hello Paketes übereinstimmen,
+	// sofern die Gegenseite ihre Identität gewechselt hat, muss der Signer Key der Nachfolger sein.
+	// Die Nachfolge kann bereits bekannt sein oder aus den Einträgen des Hello Paketes hervorgehen
+	helloSuccessionRecords := _DecodeHelloSuccessionRecords(controlStream.GetDestinationSuccessionRecords())
+	tlsPeerAddress, err := _PeerAddressFromTransportConn(o.GetRevocationStore(), conn)
+	if err != nil {
+		return nil, err
+	}
+	if !tlsPeerAddress.Equal(controlStream.GetDestinationAddress()) && !o._IsSuccessorAddress(tlsPeerAddress, controlStream.GetDestinationAddress()) && !o._IsSuccessorInRecords(helloSuccessionRecords, tlsPeerAddress, controlStream.GetDestinationAddress()) {
+		return nil, crypto.ErrPeerIdentityMismatch
+	}
+
+	// Die TLS Konfiguration des Aufrufers kann einen anderen Store verwenden, daher wird der Store des Nodes geprüft
+	for _, peerAddress := range []*crypto.OpenKeyP2PAddress{tlsPeerAddress, controlStream.GetDestinationAddress()} {
+		if o.IsAddressRevoked(peerAddress) {
+			return nil, fmt.Errorf("%w: %s", crypto.ErrAddressRevoked, peerAddress.ToString())
+		}
+	}
+
+	// Erst nach Arbeitsnachweis und Identitätsprüfung werden die Nachfolge Einträge der Gegenseite übernommen
+	o._AcceptSuccessionRecords(helloSuccessionRecords)
+
 	// Es wird geprüft ob die Version unterstützt wird (LOKAL)
 	localAcceptRemoteVersion := slices.Contains(openkeyp2p.SUPPORTED_VERSION, controlStream.GetDestinationVersion())
 	if !localAcceptRemoteVersion {
@@ -40,6 +90,44 @@ func _InitQUICNodeConn(localhostNetworkInterface *net.Interface, ctx context.Con
 		return nil, fmt.Errorf("remote peer dosent accept the local peer version")
 	}
 
+	// Ausgehende Verbindungen lösen die Challenge erst nachdem die Identität des Listeners geprüft wurde
+	var remotePOWDifficulty uint8
+	if remoteChallenge := controlStream.GetDestinationPOWChallenge(); !isIncommingConnection && remoteChallenge != nil {
+		if err := o._SendPOWSolution(ctx, controlStream); err != nil {
+			return nil, err
+		}
+		remotePOWDifficulty = remoteChallenge.Difficulty
+	}
+
+	// Die Adresse der Gegenseite wird erst nach dem Arbeitsnachweis gegen das Adressbuch geprüft, damit nicht
+	// zugelassene Verbindungen keine Einträge erzeugen. Bei eingehenden Verbindungen ist der Port der
+	// Gegenseite zufällig, daher wird nur bei ausgehenden Verbindungen der Endpunkt zugeordnet
+	addressBookEndpoint := ""
+	if !isIncommingConnection {
+		addressBookEndpoint = conn.RemoteAddr().String()
+	}
+	if err := o._ConsultAddressBook(controlStream.GetDestinationAddress(), addressBookEndpoint, NodeP2PSocketAddress(localEndpointStr), NodeP2PSocketAddress(remoteEndpointStr)); err != nil {
+		return nil, err
+	}
+
+	// Die Ende-zu-Ende Sitzung wird aus dem signierten EncryptionKey der Gegenseite erzeugt
+	payloadSession, err := o._NewPayloadSessionForPeer(controlStream.GetDestinationAddress(), controlStream.GetDestinationEncryptionKey())
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	// Unzuverlässige Datagramme werden nur genutzt wenn beide Seiten sie anbieten, sie erhalten eine eigene Sitzung
+	// damit verlorene oder vertauschte Datagramme das Replay Fenster der zuverlässigen Datagramme nicht verschieben
+	var unreliablePayloadSession *crypto.PayloadSession
+	datagramMTU := 0
+	if conn.SupportsDatagrams() && controlStream.GetDatagramSupport() {
+		unreliablePayloadSession, err = o._NewPayloadSessionForPeer(controlStream.GetDestinationAddress(), controlStream.GetDestinationEncryptionKey())
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key: %w", err)
+		}
+		datagramMTU = min(conn.MTU(), int(controlStream.GetMTU()))
+	}
+
 	// Die Gemeinsam Unterstützen Funktionen werden ermittelt
 	connectionConfig := _DeterminesCommonConfig(controlStream.destPeerHelloPacket.NodeConfigOptions, config)
 
@@ -47,7 +135,7 @@ func _InitQUICNodeConn(localhostNetworkInterface *net.Interface, ctx context.Con
 	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Control Streams opened %s -> %s", localEndpointStr, remoteEndpointStr)
 
 	// Die Package Traffic Strams werden geöffnet
-	trafficStream, err := _TryOpenP2PConnectionTrafficStream(isIncommingConnection, conn, NodeP2PSocketAddress(localEndpointStr), NodeP2PSocketAddress(remoteEndpointStr), ctx, cancel)
+	trafficStream, err := o._TryOpenP2PConnectionTrafficStream(isIncommingConnection, conn, controlStream.GetDestinationAddress(), NodeP2PSocketAddress(localEndpointStr), NodeP2PSocketAddress(remoteEndpointStr), ctx, cancel)
 	if err != nil {
 		return nil, err
 	}
@@ -55,20 +143,41 @@ func _InitQUICNodeConn(localhostNetworkInterface *net.Interface, ctx context.Con
 	// Log
 	logging.LogDebug(openkeyp2p.LOG_LEVEL_P2P, "Package Traffic Streams opened %s -> %s", localEndpointStr, remoteEndpointStr)
 
+	// Jede Verbindung erhält eine eindeutige ID
+	connectionId, err := _NewConnectionId()
+	if err != nil {
+		return nil, err
+	}
+
 	// Die Verbindung wird erzeugt
 	nodeConn := &NodeP2PConnection{
-		conn:                    conn,
-		config:                  connectionConfig,
-		ctx:                     ctx,
-		localKeepalivePacketIds: new(sync.Map),
-		contextCancel:           cancel,
-		isIncommingConnection:   isIncommingConnection,
-		controlStream:           controlStream,
-		packageTrafficStream:    trafficStream,
-		writerControlBuffer:     openkeyp2p.NewThreadSafeContextBuffer(ctx),
-		keepaliveTime:           12 * time.Second,
-		localSocketAddress:      NodeP2PSocketAddress(localEndpointStr),
-		remoteSocketAddress:     NodeP2PSocketAddress(remoteEndpointStr),
+		id:                       connectionId,
+		establishedAt:            time.Now(),
+		conn:                     conn,
+		config:                   connectionConfig,
+		ctx:                      ctx,
+		localKeepalivePacketIds:  new(sync.Map),
+		contextCancel:            cancel,
+		isIncommingConnection:    isIncommingConnection,
+		controlStream:            controlStream,
+		packageTrafficStream:     trafficStream,
+		writerControlBuffer:      openkeyp2p.NewThreadSafeContextBuffer(ctx),
+		writerTrafficBuffer:      openkeyp2p.NewBoundedThreadSafeContextBuffer(ctx, trafficWriterBufferSize),
+		keepaliveTime:            o.keepaliveTime,
+		localSocketAddress:       NodeP2PSocketAddress(localEndpointStr),
+		remoteSocketAddress:      NodeP2PSocketAddress(remoteEndpointStr),
+		payloadSession:           payloadSession,
+		unreliablePayloadSession: unreliablePayloadSession,
+		datagramMTU:              datagramMTU,
+		powBaseDifficulty:        powBaseDifficulty,
+		node:                     o,
+		routines:                 new(sync.WaitGroup),
+	}
+	nodeConn.remotePOWDifficulty.Store(uint32(remotePOWDifficulty))
+
+	// Die lokal bekannten Widerrufe werden an die Gegenseite übertragen
+	if err := _QueueKnownRevocations(nodeConn); err != nil {
+		return nil, err
 	}
 
 	// Die Verbindung wird zurückgegeben
@@ -3,10 +3,13 @@ package p2p
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
-	"github.com/quic-go/quic-go"
+	"github.com/ms2sh/OpenKeyP2P/src/addressbook"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
 )
 
 type ConnectionId string
@@ -26,19 +29,83 @@ type NodeP2PConfigEntry struct {
 	Value string
 }
 
+// Ein Node besitzt seine Identität, Listener, Verbindungen und seinen Lebenszyklus,
+// mehrere Nodes können im selben Prozess betrieben werden
+type Node struct {
+	lock                   *sync.Mutex
+	signer                 crypto.OpenKeyP2PSigner
+	connections            map[ConnectionId]*NodeP2PConnection
+	listeners              []*NodeP2Listener
+	routingPayloadSessions *_RoutingSessionCache
+	identitySuccessions    map[string]*crypto.SuccessionRecord
+	localSuccessionRecords [][]byte
+	powLoad                _POWLoadState
+	maxPOWSolveDifficulty  uint8
+	addressBook            *addressbook.AddressBook
+	revocations            *crypto.RevocationStore
+	keepaliveTime          time.Duration
+	routines               *sync.WaitGroup
+	datagramHandler        DatagramHandler
+	streamHandlers         map[StreamProtocolId]StreamHandler
+	ctx                    context.Context
+	cancel                 context.CancelCauseFunc
+	shutdownCtx            context.Context
+	shutdown               context.CancelFunc
+	isClosing              bool
+	isClosed               bool
+}
+
 type NodeP2PConnection struct {
-	conn                    quic.Connection
-	controlStream           *NodeP2PControlStream
-	packageTrafficStream    *NodeP2PTrafficStream
-	config                  NodeP2PConnectionConfig
-	ctx                     context.Context
-	contextCancel           context.CancelCauseFunc
-	writerControlBuffer     *openkeyp2p.ThreadSafeContextBuffer
-	localKeepalivePacketIds *sync.Map
-	isIncommingConnection   bool
-	keepaliveTime           time.Duration
-	localSocketAddress      NodeP2PSocketAddress
-	remoteSocketAddress     NodeP2PSocketAddress
+	id                       ConnectionId
+	establishedAt            time.Time
+	conn                     transport.Conn
+	controlStream            *NodeP2PControlStream
+	packageTrafficStream     *NodeP2PTrafficStream
+	config                   NodeP2PConnectionConfig
+	ctx                      context.Context
+	contextCancel            context.CancelCauseFunc
+	writerControlBuffer      *openkeyp2p.ThreadSafeContextBuffer
+	writerTrafficBuffer      *openkeyp2p.ThreadSafeContextBuffer
+	localKeepalivePacketIds  *sync.Map
+	isIncommingConnection    bool
+	keepaliveTime            time.Duration
+	localSocketAddress       NodeP2PSocketAddress
+	remoteSocketAddress      NodeP2PSocketAddress
+	payloadSession           *crypto.PayloadSession
+	unreliablePayloadSession *crypto.PayloadSession
+	datagramMTU              int
+	node                     *Node
+	routines                 *sync.WaitGroup
+	powBaseDifficulty        uint8
+	remotePOWDifficulty      atomic.Uint32
+	revocationWindowStart    time.Time
+	revocationCount          uint32
+	successionWindowStart    time.Time
+	successionCount          uint32
+	lastKeepalive            atomic.Int64
+}
+
+type ConnectionDirection string
+
+const (
+	ConnectionDirectionIncoming ConnectionDirection = "incoming"
+	ConnectionDirectionOutgoing ConnectionDirection = "outgoing"
+)
+
+// Informationen zu einer aktiven Verbindung
+type ConnectionInfo struct {
+	Id                  ConnectionId
+	RemoteAddress       *crypto.OpenKeyP2PAddress
+	LocalSocketAddress  NodeP2PSocketAddress
+	RemoteSocketAddress NodeP2PSocketAddress
+	Version             openkeyp2p.OpenKeyP2PVesion
+	CMTU                uint16
+	UnreliableDatagrams bool
+	Config              NodeP2PConnectionConfig
+	Direction           ConnectionDirection
+	EstablishedAt       time.Time
+	Uptime              time.Duration
+	LastKeepalive       time.Time
 }
 
 type NodeP2PListenerConfig struct {
@@ -46,21 +113,22 @@ type NodeP2PListenerConfig struct {
 	AllowPrivateNetworkConnection bool
 	AllowAutoRouting              bool
 	AllowTrafficForwarding        bool
+	POWBaseDifficulty             uint8
 }
 
 type NodeP2Listener struct {
 	config   *NodeP2PListenerConfig
-	listener *quic.Listener
+	listener transport.Listener
 	lock     *sync.Mutex
 }
 
 type QuicBidirectionalStream struct {
-	inStream                quic.Stream
-	outStream               quic.Stream
+	inStream                transport.Stream
+	outStream               transport.Stream
 	ctxCancle               context.CancelCauseFunc
 	lock                    *sync.Mutex
 	ctx                     context.Context
-	quicConn                quic.Connection
+	quicConn                transport.Conn
 	writeMutex              *sync.Mutex
 	readMutex               *sync.Mutex
 	_sendHelloBytePacket    []byte
@@ -72,6 +140,7 @@ type QuicBidirectionalStream struct {
 type NodeP2PControlStream struct {
 	*QuicBidirectionalStream
 	destPeerHelloPacket L1HelloControlSteamPacket
+	destPeerAddress     *crypto.OpenKeyP2PAddress
 }
 
 type NodeP2PTrafficStream struct {
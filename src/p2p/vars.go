@@ -1,29 +1,24 @@
 package p2p
 
-import "sync"
+import (
+	"sync"
 
-var (
-	nodeConnections map[ConnectionId]*NodeP2PConnection
-	controlLock     *sync.Mutex = new(sync.Mutex)
-	wasSetuped      bool        = false
+	"github.com/ms2sh/OpenKeyP2P/src/addressbook"
 )
 
-func _VarsAddNodeConnection(nodeConn *NodeP2PConnection) error {
-	controlLock.Lock()
-	defer controlLock.Unlock()
-	nodeConnections[nodeConn.GetConnectionId()] = nodeConn
-	return nil
-}
+// Die Paketfunktionen (Setup, AddListener, ConnectTo, Close, ...) verwenden den Standard Node
+var (
+	defaultNode        *Node
+	defaultAddressBook *addressbook.AddressBook
+	controlLock        *sync.Mutex = new(sync.Mutex)
+)
 
-func _VarsDeleteNodeConnection(nodeConn *NodeP2PConnection) {
+// Gibt den Standard Node zurück, wurde Setup() nicht aufgerufen wird ErrNodeNotSetup zurückgegeben
+func _VarsGetDefaultNode() (*Node, error) {
 	controlLock.Lock()
 	defer controlLock.Unlock()
-	nodeConnections[nodeConn.GetConnectionId()] = nodeConn
-}
-
-func _VarsWasSetuped() bool {
-	controlLock.Lock()
-	reval := wasSetuped
-	controlLock.Unlock()
-	return reval
+	if defaultNode == nil {
+		return nil, ErrNodeNotSetup
+	}
+	return defaultNode, nil
 }
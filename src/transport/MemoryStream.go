@@ -0,0 +1,174 @@
+package transport
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Segment welches zu einem Zeitpunkt beim Leser eintrifft
+type _MemorySegment struct {
+	data      []byte
+	deliverAt time.Time
+}
+
+// Eine Richtung eines Streams
+type _MemoryPipe struct {
+	lock          *sync.Mutex
+	notify        chan struct{}
+	segments      []_MemorySegment
+	buffer        []byte
+	lastDeliverAt time.Time
+	finished      bool
+	err           error
+	readDeadline  time.Time
+}
+
+type _MemoryStream struct {
+	conn *_MemoryConn
+	in   *_MemoryPipe
+	out  *_MemoryPipe
+}
+
+func _NewMemoryPipe() *_MemoryPipe {
+	return &_MemoryPipe{lock: new(sync.Mutex), notify: make(chan struct{})}
+}
+
+// Weckt alle wartenden Leser, muss mit gehaltenem Lock aufgerufen werden
+func (o *_MemoryPipe) _Signal() {
+	close(o.notify)
+	o.notify = make(chan struct{})
+}
+
+// Fügt ein Segment an, die Reihenfolge bleibt auch bei erneut gesendeten Segmenten erhalten
+func (o *_MemoryPipe) _Push(data []byte, deliverAt time.Time) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.err != nil {
+		return o.err
+	}
+	if o.finished {
+		return io.ErrClosedPipe
+	}
+	if deliverAt.Before(o.lastDeliverAt) {
+		deliverAt = o.lastDeliverAt
+	}
+	o.lastDeliverAt = deliverAt
+	o.segments = append(o.segments, _MemorySegment{data: data, deliverAt: deliverAt})
+	o._Signal()
+	return nil
+}
+
+// Beendet die Schreibrichtung, der Leser erhält io.EOF nachdem alle Segmente eingetroffen sind
+func (o *_MemoryPipe) _Finish() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.finished = true
+	o._Signal()
+}
+
+// Bricht die Pipe ab, noch nicht gelesene Daten werden verworfen
+func (o *_MemoryPipe) _Abort(err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.err == nil {
+		o.err = err
+		o.segments = nil
+		o.buffer = nil
+		o._Signal()
+	}
+}
+
+func (o *_MemoryPipe) _Read(p []byte) (int, error) {
+	for {
+		o.lock.Lock()
+		if o.err != nil {
+			err := o.err
+			o.lock.Unlock()
+			return 0, err
+		}
+
+		// Eingetroffene Segmente werden in den Lesepuffer übernommen
+		now := time.Now()
+		for len(o.segments) > 0 && !o.segments[0].deliverAt.After(now) {
+			o.buffer = append(o.buffer, o.segments[0].data...)
+			o.segments = o.segments[1:]
+		}
+		if len(o.buffer) > 0 {
+			n := copy(p, o.buffer)
+			o.buffer = o.buffer[n:]
+			o.lock.Unlock()
+			return n, nil
+		}
+		if o.finished && len(o.segments) == 0 {
+			o.lock.Unlock()
+			return 0, io.EOF
+		}
+
+		// Es wird bis zum nächsten Segment, einer Änderung oder der Deadline gewartet
+		var wait time.Duration = -1
+		if len(o.segments) > 0 {
+			wait = o.segments[0].deliverAt.Sub(now)
+		}
+		if !o.readDeadline.IsZero() {
+			untilDeadline := o.readDeadline.Sub(now)
+			if untilDeadline <= 0 {
+				o.lock.Unlock()
+				return 0, os.ErrDeadlineExceeded
+			}
+			if wait < 0 || untilDeadline < wait {
+				wait = untilDeadline
+			}
+		}
+		notify := o.notify
+		o.lock.Unlock()
+
+		if wait < 0 {
+			<-notify
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-notify:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (o *_MemoryStream) Read(p []byte) (int, error) {
+	return o.in._Read(p)
+}
+
+// Teilt die Daten in Segmente der MTU Größe, ist die Leitung ausgelastet wird gewartet bis sie frei ist
+func (o *_MemoryStream) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		size := min(len(p)-written, o.conn.config.MTU)
+		start, deliverAt := o.conn.outLink._Schedule(size)
+		if err := _SleepContext(o.conn.ctx, time.Until(start)); err != nil {
+			return written, o.conn._CloseCause()
+		}
+		segment := append([]byte{}, p[written:written+size]...)
+		if err := o.out._Push(segment, deliverAt); err != nil {
+			return written, err
+		}
+		written += size
+	}
+	return written, nil
+}
+
+func (o *_MemoryStream) Close() error {
+	o.out._Finish()
+	return nil
+}
+
+func (o *_MemoryStream) SetReadDeadline(t time.Time) error {
+	o.in.lock.Lock()
+	defer o.in.lock.Unlock()
+	o.in.readDeadline = t
+	o.in._Signal()
+	return nil
+}
@@ -0,0 +1,460 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// Netzwerk im Speicher für Tests. Verbindungen zwischen Listenern und Dialern desselben MemoryNetwork
+// verhalten sich wie Quic Verbindungen: Streams sind zuverlässig und geordnet, Latenz, Verlust und
+// Bandbreite der Verbindung werden nachgebildet. Ein verlorenes Segment wird wie bei Quic erneut
+// gesendet, es verzögert daher alle nachfolgenden Daten des Streams. Ein verlorenes Datagramm wird
+// verworfen.
+//
+// Die Zertifikate aus den TLS Konfigurationen beider Seiten werden ausgetauscht und mit deren
+// VerifyPeerCertificate Funktionen geprüft, ein TLS Handshake findet nicht statt.
+
+// Standard Nutzdatengröße eines Segments
+const DefaultMemoryMTU = 1350
+
+// Mindestwartezeit bis ein verlorenes Segment erneut gesendet wird
+const memoryMinRetransmitTimeout = 10 * time.Millisecond
+
+// Maximale Anzahl an Streams welche noch nicht von der Gegenseite angenommen wurden
+const memoryAcceptQueueSize = 64
+
+// Maximale Anzahl an Datagrammen welche noch nicht gelesen wurden, weitere werden verworfen
+const memoryDatagramQueueSize = 128
+
+type MemoryLinkConfig struct {
+	// Einweg Latenz
+	Latency time.Duration
+
+	// Wahrscheinlichkeit (0 bis unter 1) dass ein Segment verloren geht und erneut gesendet werden muss
+	LossRate float64
+
+	// Bytes pro Sekunde je Richtung, 0 bedeutet unbegrenzt
+	Bandwidth int64
+
+	// Nutzdatengröße eines Segments, 0 bedeutet DefaultMemoryMTU
+	MTU int
+
+	// Verbindungen bieten keine unzuverlässigen Datagramme an
+	DisableDatagrams bool
+}
+
+// Adresse eines Endpunkts im MemoryNetwork
+type MemoryAddr string
+
+func (o MemoryAddr) Network() string {
+	return "memory"
+}
+
+func (o MemoryAddr) String() string {
+	return string(o)
+}
+
+type MemoryNetwork struct {
+	config    MemoryLinkConfig
+	lock      *sync.Mutex
+	listeners map[string]*_MemoryListener
+	nextPort  uint64
+}
+
+type _MemoryListener struct {
+	network   *MemoryNetwork
+	addr      MemoryAddr
+	tlsConfig *tls.Config
+	conns     chan *_MemoryConn
+	closed    chan struct{}
+	closeOnce *sync.Once
+}
+
+// Eine Senderichtung der Verbindung, die Bandbreite wird von allen Streams geteilt
+type _MemoryLink struct {
+	config   MemoryLinkConfig
+	lock     *sync.Mutex
+	nextFree time.Time
+}
+
+type _MemoryConn struct {
+	local     MemoryAddr
+	remote    MemoryAddr
+	config    MemoryLinkConfig
+	outLink   *_MemoryLink
+	peer      *_MemoryConn
+	peerCerts []*x509.Certificate
+	streams   chan *_MemoryStream
+	datagrams chan []byte
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	lock      *sync.Mutex
+	pipes     []*_MemoryPipe
+}
+
+// Erzeugt ein leeres Netzwerk, alle Verbindungen verwenden die angegebenen Eigenschaften
+func NewMemoryNetwork(config MemoryLinkConfig) (*MemoryNetwork, error) {
+	// Bei einer Verlustrate von 1 würde jedes Segment endlos erneut gesendet
+	if !(config.LossRate >= 0 && config.LossRate < 1) {
+		return nil, fmt.Errorf("%w: loss rate %v must be at least 0 and below 1", ErrInvalidLinkConfig, config.LossRate)
+	}
+	if config.Latency < 0 || config.Bandwidth < 0 {
+		return nil, fmt.Errorf("%w: latency and bandwidth must not be negative", ErrInvalidLinkConfig)
+	}
+	if config.MTU <= 0 {
+		config.MTU = DefaultMemoryMTU
+	}
+	return &MemoryNetwork{
+		config:    config,
+		lock:      new(sync.Mutex),
+		listeners: make(map[string]*_MemoryListener),
+	}, nil
+}
+
+// Startet einen Listener unter der Adresse, tlsConfig muss ein Zertifikat enthalten
+func (o *MemoryNetwork) Listen(address string, tlsConfig *tls.Config) (Listener, error) {
+	if _, err := _MemoryCertificates(tlsConfig); err != nil {
+		return nil, err
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if _, found := o.listeners[address]; found {
+		return nil, fmt.Errorf("%w: %s", ErrAddressInUse, address)
+	}
+	listener := &_MemoryListener{
+		network:   o,
+		addr:      MemoryAddr(address),
+		tlsConfig: tlsConfig,
+		conns:     make(chan *_MemoryConn, memoryAcceptQueueSize),
+		closed:    make(chan struct{}),
+		closeOnce: new(sync.Once),
+	}
+	o.listeners[address] = listener
+	return listener, nil
+}
+
+// Baut eine Verbindung zu einem Listener auf, der Verbindungsaufbau dauert eine Round Trip Time
+func (o *MemoryNetwork) Dial(ctx context.Context, address string, tlsConfig *tls.Config) (Conn, error) {
+	clientCerts, err := _MemoryCertificates(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	o.lock.Lock()
+	listener, found := o.listeners[address]
+	o.nextPort++
+	localAddr := MemoryAddr(fmt.Sprintf("dialer-%d", o.nextPort))
+	o.lock.Unlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrConnectionRefused, address)
+	}
+	serverCerts, _ := _MemoryCertificates(listener.tlsConfig)
+
+	// Wie bei TLS kann der Listener für jeden Verbindenden eine eigene Konfiguration festlegen
+	serverTLSConfig := listener.tlsConfig
+	if serverTLSConfig.GetConfigForClient != nil {
+		clientConfig, err := serverTLSConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+		if err != nil {
+			return nil, err
+		}
+		if clientConfig != nil {
+			serverTLSConfig = clientConfig
+		}
+	}
+
+	// Beide Seiten prüfen das Zertifikat der Gegenseite
+	if tlsConfig.VerifyPeerCertificate != nil {
+		if err := tlsConfig.VerifyPeerCertificate(_RawCertificates(serverCerts), nil); err != nil {
+			return nil, err
+		}
+	}
+	if serverTLSConfig.VerifyPeerCertificate != nil {
+		if err := serverTLSConfig.VerifyPeerCertificate(_RawCertificates(clientCerts), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// Der Verbindungsaufbau benötigt eine Round Trip Time
+	if err := _SleepContext(ctx, 2*o.config.Latency); err != nil {
+		return nil, err
+	}
+
+	clientConn := _NewMemoryConn(localAddr, listener.addr, o.config, serverCerts)
+	serverConn := _NewMemoryConn(listener.addr, localAddr, o.config, clientCerts)
+	clientConn.peer = serverConn
+	serverConn.peer = clientConn
+
+	select {
+	case listener.conns <- serverConn:
+		return clientConn, nil
+	case <-listener.closed:
+		return nil, fmt.Errorf("%w: %s", ErrConnectionRefused, address)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (o *_MemoryListener) Accept(ctx context.Context) (Conn, error) {
+	select {
+	case conn := <-o.conns:
+		return conn, nil
+	case <-o.closed:
+		return nil, ErrListenerClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (o *_MemoryListener) Addr() net.Addr {
+	return o.addr
+}
+
+func (o *_MemoryListener) Close() error {
+	o.closeOnce.Do(func() {
+		o.network.lock.Lock()
+		delete(o.network.listeners, string(o.addr))
+		o.network.lock.Unlock()
+		close(o.closed)
+	})
+	return nil
+}
+
+func _NewMemoryConn(local MemoryAddr, remote MemoryAddr, config MemoryLinkConfig, peerCerts []*x509.Certificate) *_MemoryConn {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &_MemoryConn{
+		local:     local,
+		remote:    remote,
+		config:    config,
+		outLink:   &_MemoryLink{config: config, lock: new(sync.Mutex)},
+		peerCerts: peerCerts,
+		streams:   make(chan *_MemoryStream, memoryAcceptQueueSize),
+		datagrams: make(chan []byte, memoryDatagramQueueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+		lock:      new(sync.Mutex),
+	}
+}
+
+func (o *_MemoryConn) OpenStreamSync(ctx context.Context) (Stream, error) {
+	if err := context.Cause(o.ctx); err != nil {
+		return nil, err
+	}
+
+	// Jede Richtung des Streams erhält eine eigene Pipe
+	outPipe := _NewMemoryPipe()
+	inPipe := _NewMemoryPipe()
+	o._TrackPipes(outPipe, inPipe)
+	o.peer._TrackPipes(outPipe, inPipe)
+
+	localStream := &_MemoryStream{conn: o, in: inPipe, out: outPipe}
+	remoteStream := &_MemoryStream{conn: o.peer, in: outPipe, out: inPipe}
+
+	select {
+	case o.peer.streams <- remoteStream:
+		return localStream, nil
+	case <-o.ctx.Done():
+		return nil, context.Cause(o.ctx)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (o *_MemoryConn) AcceptStream(ctx context.Context) (Stream, error) {
+	select {
+	case stream := <-o.streams:
+		return stream, nil
+	case <-o.ctx.Done():
+		return nil, context.Cause(o.ctx)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (o *_MemoryConn) LocalAddr() net.Addr {
+	return o.local
+}
+
+func (o *_MemoryConn) RemoteAddr() net.Addr {
+	return o.remote
+}
+
+func (o *_MemoryConn) MTU() int {
+	return o.config.MTU
+}
+
+func (o *_MemoryConn) PeerCertificates() []*x509.Certificate {
+	return o.peerCerts
+}
+
+func (o *_MemoryConn) SupportsDatagrams() bool {
+	return !o.config.DisableDatagrams
+}
+
+// Belegt die Leitung wie ein Stream Segment, das Datagramm wird aber nicht erneut gesendet wenn es verloren geht
+func (o *_MemoryConn) SendDatagram(payload []byte) error {
+	if !o.SupportsDatagrams() {
+		return ErrNoDatagramSupport
+	}
+	if len(payload) > o.config.MTU {
+		return fmt.Errorf("%w: %d bytes, maximum %d bytes", ErrDatagramTooLarge, len(payload), o.config.MTU)
+	}
+
+	start, deliverAt, lost := o.outLink._ScheduleDatagram(len(payload))
+	if err := _SleepContext(o.ctx, time.Until(start)); err != nil {
+		return o._CloseCause()
+	}
+	if lost {
+		return nil
+	}
+
+	datagram := append([]byte{}, payload...)
+	peer := o.peer
+	time.AfterFunc(time.Until(deliverAt), func() {
+		if peer.ctx.Err() != nil {
+			return
+		}
+		select {
+		case peer.datagrams <- datagram:
+		default:
+		}
+	})
+	return nil
+}
+
+func (o *_MemoryConn) ReceiveDatagram(ctx context.Context) ([]byte, error) {
+	if !o.SupportsDatagrams() {
+		return nil, ErrNoDatagramSupport
+	}
+	select {
+	case datagram := <-o.datagrams:
+		return datagram, nil
+	case <-o.ctx.Done():
+		return nil, context.Cause(o.ctx)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Schließt beide Seiten der Verbindung, noch nicht gelesene Daten werden verworfen
+func (o *_MemoryConn) CloseWithError(code ApplicationErrorCode, reason string) error {
+	o._Close(&ApplicationError{Remote: false, Code: code, Reason: reason})
+	o.peer._Close(&ApplicationError{Remote: true, Code: code, Reason: reason})
+	return nil
+}
+
+func (o *_MemoryConn) _Close(err error) {
+	o.lock.Lock()
+	if o.ctx.Err() != nil {
+		o.lock.Unlock()
+		return
+	}
+	o.cancel(err)
+	pipes := o.pipes
+	o.pipes = nil
+	o.lock.Unlock()
+
+	for _, pipe := range pipes {
+		pipe._Abort(err)
+	}
+}
+
+// Gibt den Grund zurück mit dem die Verbindung geschlossen wurde
+func (o *_MemoryConn) _CloseCause() error {
+	if err := context.Cause(o.ctx); err != nil {
+		return err
+	}
+	return io.ErrClosedPipe
+}
+
+func (o *_MemoryConn) _TrackPipes(pipes ...*_MemoryPipe) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.pipes = append(o.pipes, pipes...)
+}
+
+// Gibt an wann ein Segment der Größe size bei der Gegenseite eintrifft und wann es die Leitung belegt
+func (o *_MemoryLink) _Schedule(size int) (start time.Time, deliverAt time.Time) {
+	start, deliverAt = o._Reserve(size)
+
+	// Verlorene Segmente werden nach Ablauf der Retransmit Zeit erneut gesendet
+	retransmitTimeout := max(2*o.config.Latency, memoryMinRetransmitTimeout)
+	for o._IsLost() {
+		deliverAt = deliverAt.Add(retransmitTimeout)
+	}
+	return start, deliverAt
+}
+
+// Wie _Schedule, ein verlorenes Datagramm wird jedoch nicht erneut gesendet
+func (o *_MemoryLink) _ScheduleDatagram(size int) (start time.Time, deliverAt time.Time, lost bool) {
+	start, deliverAt = o._Reserve(size)
+	return start, deliverAt, o._IsLost()
+}
+
+// Belegt die Leitung für die Übertragungsdauer von size Bytes
+func (o *_MemoryLink) _Reserve(size int) (start time.Time, deliverAt time.Time) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	now := time.Now()
+	start = now
+	if o.nextFree.After(now) {
+		start = o.nextFree
+	}
+
+	var transmission time.Duration
+	if o.config.Bandwidth > 0 {
+		transmission = time.Duration(int64(size) * int64(time.Second) / o.config.Bandwidth)
+	}
+	o.nextFree = start.Add(transmission)
+	return start, o.nextFree.Add(o.config.Latency)
+}
+
+func (o *_MemoryLink) _IsLost() bool {
+	return o.config.LossRate > 0 && rand.Float64() < o.config.LossRate
+}
+
+func _MemoryCertificates(tlsConfig *tls.Config) ([]*x509.Certificate, error) {
+	if tlsConfig == nil || len(tlsConfig.Certificates) == 0 || len(tlsConfig.Certificates[0].Certificate) == 0 {
+		return nil, ErrNoCertificate
+	}
+	reval := make([]*x509.Certificate, 0, len(tlsConfig.Certificates[0].Certificate))
+	for _, raw := range tlsConfig.Certificates[0].Certificate {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, err
+		}
+		reval = append(reval, cert)
+	}
+	return reval, nil
+}
+
+func _RawCertificates(certs []*x509.Certificate) [][]byte {
+	reval := make([][]byte, 0, len(certs))
+	for _, cert := range certs {
+		reval = append(reval, cert.Raw)
+	}
+	return reval
+}
+
+func _SleepContext(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
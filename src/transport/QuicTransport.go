@@ -0,0 +1,202 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/quic-go/quic-go"
+)
+
+type _QuicConn struct {
+	conn quic.Connection
+	mtu  int
+}
+
+// Unzuverlässige Datagramme werden immer angeboten, ob sie genutzt werden handelt der Node im Hello Paket aus
+var quicConfig = &quic.Config{EnableDatagrams: true}
+
+type _QuicListener struct {
+	listener *quic.Listener
+	udpConn  *net.UDPConn
+}
+
+// Erzeugt aus einer Quic Verbindung eine Transport Verbindung, die MTU wird anhand des
+// Netzwerkinterfaces der lokalen IP Adresse bestimmt
+func NewQuicConn(conn quic.Connection) (Conn, error) {
+	localAddr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok {
+		return nil, fmt.Errorf("unsupported local address %s", conn.LocalAddr())
+	}
+
+	// Bei Wildcard Adressen wird die tatsächlich verwendete Adresse ermittelt
+	ip := localAddr.IP
+	if ip.IsUnspecified() {
+		ip = _GetDefaultLocalIP()
+	}
+	localhostNetworkInterface, err := _GetInterfaceByIP(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	return &_QuicConn{
+		conn: conn,
+		mtu:  openkeyp2p.CalculateQUICPayloadSize(localhostNetworkInterface.MTU, ip.To4() == nil),
+	}, nil
+}
+
+// Baut eine Quic Verbindung auf
+func DialQuic(ctx context.Context, address string, tlsConfig *tls.Config) (Conn, error) {
+	conn, err := quic.DialAddr(ctx, address, tlsConfig, quicConfig)
+	if err != nil {
+		return nil, err
+	}
+	reval, err := NewQuicConn(conn)
+	if err != nil {
+		conn.CloseWithError(0, err.Error())
+		return nil, err
+	}
+	return reval, nil
+}
+
+// Startet einen Quic Listener auf der UDP Adresse
+func ListenQuic(address string, tlsConfig *tls.Config) (Listener, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	udpConn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := quic.Listen(udpConn, tlsConfig, quicConfig)
+	if err != nil {
+		udpConn.Close()
+		return nil, err
+	}
+	return &_QuicListener{listener: listener, udpConn: udpConn}, nil
+}
+
+func (o *_QuicConn) OpenStreamSync(ctx context.Context) (Stream, error) {
+	return o.conn.OpenStreamSync(ctx)
+}
+
+func (o *_QuicConn) AcceptStream(ctx context.Context) (Stream, error) {
+	return o.conn.AcceptStream(ctx)
+}
+
+func (o *_QuicConn) LocalAddr() net.Addr {
+	return o.conn.LocalAddr()
+}
+
+func (o *_QuicConn) RemoteAddr() net.Addr {
+	return o.conn.RemoteAddr()
+}
+
+func (o *_QuicConn) MTU() int {
+	return o.mtu
+}
+
+func (o *_QuicConn) PeerCertificates() []*x509.Certificate {
+	return o.conn.ConnectionState().TLS.PeerCertificates
+}
+
+func (o *_QuicConn) SupportsDatagrams() bool {
+	return o.conn.ConnectionState().SupportsDatagrams
+}
+
+func (o *_QuicConn) SendDatagram(payload []byte) error {
+	if !o.SupportsDatagrams() {
+		return ErrNoDatagramSupport
+	}
+	err := o.conn.SendDatagram(payload)
+	var tooLargeErr *quic.DatagramTooLargeError
+	if errors.As(err, &tooLargeErr) {
+		return fmt.Errorf("%w: %d bytes, maximum %d bytes", ErrDatagramTooLarge, len(payload), tooLargeErr.MaxDatagramPayloadSize)
+	}
+	return err
+}
+
+func (o *_QuicConn) ReceiveDatagram(ctx context.Context) ([]byte, error) {
+	if !o.SupportsDatagrams() {
+		return nil, ErrNoDatagramSupport
+	}
+	return o.conn.ReceiveDatagram(ctx)
+}
+
+func (o *_QuicConn) CloseWithError(code ApplicationErrorCode, reason string) error {
+	return o.conn.CloseWithError(quic.ApplicationErrorCode(code), reason)
+}
+
+func (o *_QuicListener) Accept(ctx context.Context) (Conn, error) {
+	session, err := o.listener.Accept(ctx)
+	if err != nil {
+		if errors.Is(err, quic.ErrServerClosed) {
+			return nil, ErrListenerClosed
+		}
+		return nil, err
+	}
+
+	conn, err := NewQuicConn(session)
+	if err != nil {
+		session.CloseWithError(0, err.Error())
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (o *_QuicListener) Addr() net.Addr {
+	return o.listener.Addr()
+}
+
+func (o *_QuicListener) Close() error {
+	err := o.listener.Close()
+	o.udpConn.Close()
+	return err
+}
+
+// Gibt die erste IPv4 Adresse zurück welche kein Loopback ist
+func _GetDefaultLocalIP() net.IP {
+	ips, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, ip := range ips {
+			if ipnet, ok := ip.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+				return ipnet.IP
+			}
+		}
+	}
+	return net.IPv4(127, 0, 0, 1)
+}
+
+// Ermittelt das Netzwerkinterface anhand einer gegebenen IP-Adresse
+func _GetInterfaceByIP(ip net.IP) (*net.Interface, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			switch v := addr.(type) {
+			case *net.IPNet:
+				if v.IP.Equal(ip) {
+					return &iface, nil
+				}
+			case *net.IPAddr:
+				if v.IP.Equal(ip) {
+					return &iface, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNoNetworkInterface, ip)
+}
@@ -0,0 +1,76 @@
+package transport
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Transportschicht unter einer Node Verbindung. Eine Verbindung stellt zuverlässige, geordnete
+// bidirektionale Streams bereit und kennt die Zertifikate der Gegenseite aus dem Verbindungsaufbau.
+// Optional können unzuverlässige Datagramme (RFC 9221) übertragen werden, diese können verloren gehen
+// und in anderer Reihenfolge eintreffen.
+// Implementiert wird sie von Quic (QuicTransport.go) sowie von einem Netzwerk im Speicher für Tests
+// (MemoryTransport.go).
+
+// Fehlercode mit dem eine Verbindung von der Anwendung geschlossen wird
+type ApplicationErrorCode uint64
+
+type Stream interface {
+	io.Reader
+	io.Writer
+
+	// Beendet die Schreibrichtung, die Gegenseite liest danach io.EOF
+	Close() error
+
+	SetReadDeadline(t time.Time) error
+}
+
+type Conn interface {
+	OpenStreamSync(ctx context.Context) (Stream, error)
+	AcceptStream(ctx context.Context) (Stream, error)
+	LocalAddr() net.Addr
+	RemoteAddr() net.Addr
+
+	// Maximale Nutzdatengröße eines Paketes
+	MTU() int
+
+	// Zertifikate welche die Gegenseite beim Verbindungsaufbau vorgelegt hat
+	PeerCertificates() []*x509.Certificate
+
+	// Gibt an ob beide Seiten unzuverlässige Datagramme unterstützen
+	SupportsDatagrams() bool
+
+	// Sendet ein unzuverlässiges Datagramm, ist die Sendewarteschlange voll wird gewartet
+	SendDatagram(payload []byte) error
+
+	// Wartet auf ein unzuverlässiges Datagramm der Gegenseite
+	ReceiveDatagram(ctx context.Context) ([]byte, error)
+
+	CloseWithError(code ApplicationErrorCode, reason string) error
+}
+
+type Listener interface {
+	// Wartet auf eine neue Verbindung, nach Close() wird ErrListenerClosed zurückgegeben
+	Accept(ctx context.Context) (Conn, error)
+	Addr() net.Addr
+	Close() error
+}
+
+// Fehler einer Verbindung welche von der Anwendung geschlossen wurde
+type ApplicationError struct {
+	Remote bool
+	Code   ApplicationErrorCode
+	Reason string
+}
+
+func (o *ApplicationError) Error() string {
+	side := "local"
+	if o.Remote {
+		side = "remote"
+	}
+	return fmt.Sprintf("Application error %#x (%s): %s", uint64(o.Code), side, o.Reason)
+}
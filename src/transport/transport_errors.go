@@ -0,0 +1,14 @@
+package transport
+
+import "errors"
+
+var (
+	ErrListenerClosed     = errors.New("listener closed")
+	ErrAddressInUse       = errors.New("address already in use")
+	ErrConnectionRefused  = errors.New("connection refused")
+	ErrNoNetworkInterface = errors.New("no network interface found for local address")
+	ErrNoCertificate      = errors.New("tls config contains no certificate")
+	ErrNoDatagramSupport  = errors.New("datagrams are not supported by the connection")
+	ErrDatagramTooLarge   = errors.New("datagram too large")
+	ErrInvalidLinkConfig  = errors.New("invalid memory link config")
+)
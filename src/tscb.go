@@ -14,17 +14,32 @@ type ThreadSafeContextBuffer struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	closed bool
+	limit  int
 }
 
 // NewThreadSafeContextBuffer erstellt einen neuen Puffer mit Context
 func NewThreadSafeContextBuffer(ctx context.Context) *ThreadSafeContextBuffer {
+	return NewBoundedThreadSafeContextBuffer(ctx, 0)
+}
+
+// NewBoundedThreadSafeContextBuffer erstellt einen Puffer welcher höchstens limit Elemente aufnimmt,
+// ist er voll wartet Put bis wieder Platz ist. Ein limit von 0 bedeutet unbegrenzt
+func NewBoundedThreadSafeContextBuffer(ctx context.Context, limit int) *ThreadSafeContextBuffer {
 	childCtx, cancel := context.WithCancel(ctx)
 	tscb := &ThreadSafeContextBuffer{
 		buffer: list.New(),
 		ctx:    childCtx,
 		cancel: cancel,
+		limit:  limit,
 	}
 	tscb.cond = sync.NewCond(&tscb.mu)
+
+	// Wartende Leser und Schreiber werden geweckt sobald der Context beendet wird
+	context.AfterFunc(childCtx, func() {
+		tscb.mu.Lock()
+		defer tscb.mu.Unlock()
+		tscb.cond.Broadcast()
+	})
 	return tscb
 }
 
@@ -33,18 +48,26 @@ func (tscb *ThreadSafeContextBuffer) Put(data interface{}) error {
 	tscb.mu.Lock()
 	defer tscb.mu.Unlock()
 
-	select {
-	case <-tscb.ctx.Done():
-		return tscb.ctx.Err()
-	default:
-	}
+	for {
+		select {
+		case <-tscb.ctx.Done():
+			return tscb.ctx.Err()
+		default:
+		}
 
-	if tscb.closed {
-		return context.Canceled
+		if tscb.closed {
+			return context.Canceled
+		}
+
+		// Ein begrenzter Puffer nimmt erst wieder Daten auf wenn ein Element entnommen wurde
+		if tscb.limit <= 0 || tscb.buffer.Len() < tscb.limit {
+			break
+		}
+		tscb.cond.Wait()
 	}
 
 	tscb.buffer.PushBack(data)
-	tscb.cond.Signal()
+	tscb.cond.Broadcast()
 	return nil
 }
 
@@ -69,10 +92,11 @@ func (tscb *ThreadSafeContextBuffer) Get() (interface{}, error) {
 	front := tscb.buffer.Front()
 	data := front.Value
 	tscb.buffer.Remove(front)
+	tscb.cond.Broadcast()
 	return data, nil
 }
 
-// Prepend fügt Daten am Anfang des Puffers hinzu
+// Prepend fügt Daten am Anfang des Puffers hinzu, die Begrenzung wird dabei nicht beachtet
 func (tscb *ThreadSafeContextBuffer) Prepend(data interface{}) error {
 	tscb.mu.Lock()
 	defer tscb.mu.Unlock()
@@ -88,7 +112,7 @@ func (tscb *ThreadSafeContextBuffer) Prepend(data interface{}) error {
 	}
 
 	tscb.buffer.PushFront(data)
-	tscb.cond.Signal()
+	tscb.cond.Broadcast()
 	return nil
 }
 
@@ -1,9 +1,5 @@
 package openkeyp2p
 
-import (
-	"crypto/ed25519"
-)
-
 // Gibt den Prefix einer Node Adresse an
 type OpenKeyP2PPrefix string
 
@@ -13,8 +9,8 @@ type OpenKeyP2PVesion uint64
 // Stellt den Verwendeten Addresstypen dat
 type OpenKeyP2PKeyType uint8
 
-// Stellt den ED25519 PublicKey dar
-type OpenKeyP2PPublicKey ed25519.PublicKey
+// Stellt einen PublicKey dar, das Format hängt vom OpenKeyP2PKeyType ab
+type OpenKeyP2PPublicKey []byte
 
 // Stellt ein Base32 Alphabet dar
 type Base32Alphabet string
@@ -25,6 +21,9 @@ type HashAlgorithm uint16
 // Stellt einen Hash dar
 type HashSlice []byte
 
+// Stellt einen selbstbeschreibenden Hash im Multihash Format dar (Code, Länge, Digest)
+type Multihash []byte
+
 // LogLevel
 type LogLevel uint8
 
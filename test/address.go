@@ -5,43 +5,50 @@ import (
 	"fmt"
 	"log"
 
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
 	"github.com/ms2sh/OpenKeyP2P/src/crypto"
 )
 
 func main() {
-	seed := make([]byte, 32)
-	if _, err := rand.Read(seed); err != nil {
-		log.Fatalf("Fehler bei der Zufallsgenerierung: %v", err)
-	}
-
-	priv, pub := crypto.GenerateRandomKeyPair(seed)
-	addr, err := crypto.OpenKeyP2PAddressFromPublicKey(pub)
-	if err != nil {
-		panic(err)
-	}
-
-	dataHash := crypto.ComputeSha256BitHash([]byte("hallo welt"))
-
-	sig, err := crypto.AddressSign(priv, dataHash)
-	if err != nil {
-		panic(err)
-	}
-
-	result, err := addr.VerifySignature(sig, dataHash)
-	if err != nil {
-		panic(err)
-	}
-
-	fmt.Println(addr.ToString(), result)
-
-	decodedAddr, err := crypto.OpenKeyP2PAddressDecodeFromString(addr.ToString())
-	if err != nil {
-		panic(err)
-	}
-	fmt.Println(decodedAddr.ToString())
-
-	_, err = crypto.OpenKeyP2PAddressDecodeFromByteSlice(decodedAddr.ToByteSlice())
-	if err != nil {
-		panic(err)
+	for _, keyType := range []openkeyp2p.OpenKeyP2PKeyType{openkeyp2p.Type_Ed25519, openkeyp2p.Type_Secp256k1, openkeyp2p.Type_BLS12381} {
+		seed := make([]byte, 32)
+		if _, err := rand.Read(seed); err != nil {
+			log.Fatalf("Fehler bei der Zufallsgenerierung: %v", err)
+		}
+
+		signer, err := crypto.NewSignerFromSeed(keyType, seed)
+		if err != nil {
+			panic(err)
+		}
+
+		addr, err := crypto.OpenKeyP2PAddressFromSigner(signer)
+		if err != nil {
+			panic(err)
+		}
+
+		dataHash := crypto.ComputeSha256BitHash([]byte("hallo welt"))
+
+		sig, err := crypto.AddressSign(signer, dataHash)
+		if err != nil {
+			panic(err)
+		}
+
+		result, err := addr.VerifySignature(sig, dataHash)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println(crypto.KeyTypeName(keyType), addr.ToString(), result)
+
+		decodedAddr, err := crypto.OpenKeyP2PAddressDecodeFromString(addr.ToString())
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(decodedAddr.ToString())
+
+		_, err = crypto.OpenKeyP2PAddressDecodeFromByteSlice(decodedAddr.ToByteSlice())
+		if err != nil {
+			panic(err)
+		}
 	}
 }
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync/atomic"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/p2p"
+	"github.com/ms2sh/OpenKeyP2P/src/transport"
+)
+
+// Verbindet zwei Nodes über ein Netzwerk im Speicher, es wird keine Netzwerkschnittstelle benötigt
+// Keepalive und Routing Kanäle werden ohne öffentliche Schnittstelle in src/p2p/MemoryTransport_test.go geprüft
+
+func newNode() (*p2p.Node, crypto.OpenKeyP2PSigner) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		panic(err)
+	}
+	nodeKey, err := crypto.NewSignerFromSeed(openkeyp2p.Type_Ed25519, seed)
+	if err != nil {
+		panic(err)
+	}
+	node, err := p2p.NewNode(nodeKey)
+	if err != nil {
+		panic(err)
+	}
+	return node, nodeKey
+}
+
+// Wartet bis condition erfüllt ist, nach 10 Sekunden wird abgebrochen
+func waitFor(what string, condition func() bool) {
+	deadline := time.Now().Add(10 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			panic(fmt.Sprintf("timed out waiting for %s", what))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func main() {
+	// 20ms Latenz, 5% Verlust und 1 MB/s je Richtung
+	network, err := transport.NewMemoryNetwork(transport.MemoryLinkConfig{
+		Latency:   20 * time.Millisecond,
+		LossRate:  0.05,
+		Bandwidth: 1 << 20,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	server, serverKey := newNode()
+	client, clientKey := newNode()
+
+	serverTLSConfig, err := crypto.GenerateNodeTLSConfig(serverKey, nil)
+	if err != nil {
+		panic(err)
+	}
+	clientTLSConfig, err := crypto.GenerateNodeTLSConfig(clientKey, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// Der Server zählt die empfangenen Datagramme des Clients
+	clientAddress, err := client.GetLocalNodeAddress()
+	if err != nil {
+		panic(err)
+	}
+	var received atomic.Int64
+	server.SetDatagramHandler(func(source *crypto.OpenKeyP2PAddress, payload []byte) {
+		if !source.Equal(clientAddress) {
+			panic("datagram from unexpected source")
+		}
+		received.Add(1)
+	})
+
+	listener, err := network.Listen("server", serverTLSConfig)
+	if err != nil {
+		panic(err)
+	}
+	if err := server.AddTransportListener(listener, &p2p.NodeP2PListenerConfig{}); err != nil {
+		panic(err)
+	}
+
+	start := time.Now()
+	conn, err := network.Dial(context.Background(), "server", clientTLSConfig)
+	if err != nil {
+		panic(err)
+	}
+	if err := client.ConnectTransport(conn, p2p.NewNodeP2PConnectionConfig()); err != nil {
+		panic(err)
+	}
+	log.Printf("Handshake finished after %s", time.Since(start))
+
+	// Die eingehende Verbindung wird auf dem Server erst nach dem Handshake registriert
+	waitFor("connection registered on both nodes", func() bool {
+		return len(client.ListConnections()) == 1 && len(server.ListConnections()) == 1
+	})
+	log.Printf("Client connections: %d, server connections: %d", len(client.ListConnections()), len(server.ListConnections()))
+
+	// Es werden mehr Datagramme gesendet als der Schreibpuffer aufnimmt, SendDatagram wartet dann auf die Leitung
+	serverAddress, err := server.GetLocalNodeAddress()
+	if err != nil {
+		panic(err)
+	}
+	const datagramCount = 1000
+	payload := make([]byte, 1024)
+	start = time.Now()
+	for range datagramCount {
+		if err := client.SendDatagram(serverAddress, payload); err != nil {
+			panic(err)
+		}
+	}
+	waitFor("reliable datagrams", func() bool {
+		return received.Load() >= datagramCount
+	})
+	if count := received.Load(); count != datagramCount {
+		panic(fmt.Sprintf("received %d/%d reliable datagrams", count, datagramCount))
+	}
+	log.Printf("Datagrams received: %d/%d after %s", received.Load(), datagramCount, time.Since(start))
+
+	// Unzuverlässige Datagramme werden bei Verlust nicht erneut gesendet, es treffen daher etwa 95% ein
+	maxSize, err := client.MaxUnreliableDatagramPayloadSize(serverAddress)
+	if err != nil {
+		panic(err)
+	}
+	received.Store(0)
+	unreliablePayload := make([]byte, maxSize)
+	for range datagramCount {
+		if err := client.SendUnreliableDatagram(serverAddress, unreliablePayload); err != nil {
+			panic(err)
+		}
+	}
+	// Es wird gewartet bis keine weiteren Datagramme mehr eintreffen
+	last := int64(-1)
+	waitFor("unreliable datagrams", func() bool {
+		time.Sleep(200 * time.Millisecond)
+		current := received.Load()
+		stable := current == last
+		last = current
+		return stable
+	})
+	if count := received.Load(); count == 0 || count > datagramCount {
+		panic(fmt.Sprintf("received %d/%d unreliable datagrams", count, datagramCount))
+	}
+	log.Printf("Unreliable datagrams of %d bytes received: %d/%d", maxSize, received.Load(), datagramCount)
+
+	// Der Server beantwortet Streams des Echo Protokolls, andere Protokolle werden abgelehnt
+	if err := server.SetStreamHandler("/echo/1.0.0", func(source *crypto.OpenKeyP2PAddress, stream io.ReadWriteCloser) {
+		defer stream.Close()
+		io.Copy(stream, stream)
+	}); err != nil {
+		panic(err)
+	}
+	stream, err := client.OpenStream(serverAddress, "/echo/1.0.0")
+	if err != nil {
+		panic(err)
+	}
+	message := bytes.Repeat([]byte("echo"), 16*1024)
+	go func() {
+		stream.Write(message)
+		stream.Close()
+	}()
+	echoed, err := io.ReadAll(stream)
+	if err != nil {
+		panic(err)
+	}
+	if !bytes.Equal(echoed, message) {
+		panic(fmt.Sprintf("echo stream returned %d/%d bytes with different content", len(echoed), len(message)))
+	}
+	log.Printf("Echo stream returned %d/%d bytes", len(echoed), len(message))
+	if _, err := client.OpenStream(serverAddress, "/unknown/1.0.0"); !errors.Is(err, p2p.ErrProtocolNotSupported) {
+		panic(fmt.Sprintf("unknown protocol was not rejected: %v", err))
+	}
+	log.Printf("Unknown stream protocol rejected")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Close(ctx); err != nil {
+		panic(err)
+	}
+	if err := server.Close(ctx); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"log"
+	"sync/atomic"
+	"time"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/p2p"
+)
+
+// Betreibt zwei Nodes im selben Prozess und verbindet sie über die Loopback Schnittstelle
+
+func newNode() (*p2p.Node, crypto.OpenKeyP2PSigner) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		panic(err)
+	}
+	nodeKey, err := crypto.NewSignerFromSeed(openkeyp2p.Type_Ed25519, seed)
+	if err != nil {
+		panic(err)
+	}
+	node, err := p2p.NewNode(nodeKey)
+	if err != nil {
+		panic(err)
+	}
+	return node, nodeKey
+}
+
+func main() {
+	server, serverKey := newNode()
+	client, clientKey := newNode()
+
+	serverTLSConfig, err := crypto.GenerateNodeTLSConfig(serverKey, nil)
+	if err != nil {
+		panic(err)
+	}
+	clientTLSConfig, err := crypto.GenerateNodeTLSConfig(clientKey, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := server.AddListener("127.0.0.1", 9960, serverTLSConfig, &p2p.NodeP2PListenerConfig{}); err != nil {
+		panic(err)
+	}
+
+	serverAddress, err := server.GetLocalNodeAddress()
+	if err != nil {
+		panic(err)
+	}
+	if err := client.ConnectTo("quic://"+serverAddress.ToString()+"@127.0.0.1:9960", clientTLSConfig, p2p.NewNodeP2PConnectionConfig()); err != nil {
+		panic(err)
+	}
+
+	// Die eingehende Verbindung wird auf dem Server erst nach dem Handshake registriert
+	time.Sleep(500 * time.Millisecond)
+	log.Printf("Client connections: %d, server connections: %d", len(client.ListConnections()), len(server.ListConnections()))
+
+	// Ein unzuverlässiges Datagramm wird über QUIC DATAGRAM Frames gesendet
+	var received atomic.Int64
+	server.SetDatagramHandler(func(source *crypto.OpenKeyP2PAddress, payload []byte) {
+		received.Add(1)
+	})
+	maxSize, err := client.MaxUnreliableDatagramPayloadSize(serverAddress)
+	if err != nil {
+		panic(err)
+	}
+	for range 10 {
+		if err := client.SendUnreliableDatagram(serverAddress, []byte("ping")); err != nil {
+			panic(err)
+		}
+	}
+	time.Sleep(200 * time.Millisecond)
+	log.Printf("Unreliable datagrams received: %d/10, maximum size %d bytes", received.Load(), maxSize)
+
+	// Ein Anwendungs Stream wird über einen eigenen QUIC Stream geöffnet
+	if err := server.SetStreamHandler("/echo/1.0.0", func(source *crypto.OpenKeyP2PAddress, stream io.ReadWriteCloser) {
+		defer stream.Close()
+		io.Copy(stream, stream)
+	}); err != nil {
+		panic(err)
+	}
+	stream, err := client.OpenStream(serverAddress, "/echo/1.0.0")
+	if err != nil {
+		panic(err)
+	}
+	stream.Write([]byte("hello"))
+	stream.Close()
+	echoed, err := io.ReadAll(stream)
+	if err != nil {
+		panic(err)
+	}
+	log.Printf("Echo stream returned %q", echoed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Close(ctx); err != nil {
+		panic(err)
+	}
+	if err := server.Close(ctx); err != nil {
+		panic(err)
+	}
+}
@@ -1,20 +1,39 @@
 package main
 
 import (
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
 	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+	"github.com/ms2sh/OpenKeyP2P/src/keystore"
 	"github.com/ms2sh/OpenKeyP2P/src/p2p"
 )
 
 func main() {
-	if err := p2p.Setup(); err != nil {
+	nodeKey, err := keystore.LoadOrCreateKeystoreFile("server.okp2pks", openkeyp2p.Type_Ed25519, []byte(os.Getenv("OKP2P_KEYSTORE_PASSPHRASE")))
+	if err != nil {
+		panic(err)
+	}
+
+	// Widerrufe bleiben über Neustarts erhalten
+	if _, err := crypto.LoadRevocationFile("server.okp2prev"); err != nil {
+		panic(err)
+	}
+
+	if err := p2p.Setup(nodeKey); err != nil {
+		panic(err)
+	}
+
+	nodeAddress, err := p2p.GetLocalNodeAddress()
+	if err != nil {
 		panic(err)
 	}
+	log.Printf("Node address: %s", nodeAddress.ToString())
 
-	tlsConfig, err := crypto.GenerateTempTLSConfig()
+	tlsConfig, err := crypto.GenerateNodeTLSConfig(nodeKey, nil)
 	if err != nil {
 		panic(err)
 	}
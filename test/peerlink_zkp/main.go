@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	openkeyp2p "github.com/ms2sh/OpenKeyP2P/src"
+	"github.com/ms2sh/OpenKeyP2P/src/crypto"
+)
+
+func _NewEd25519Signer() (crypto.Ed25519Signer, *crypto.OpenKeyP2PAddress) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		panic(err)
+	}
+
+	signer, err := crypto.NewSignerFromSeed(openkeyp2p.Type_Ed25519, seed)
+	if err != nil {
+		panic(err)
+	}
+
+	addr, err := crypto.OpenKeyP2PAddressFromSigner(signer)
+	if err != nil {
+		panic(err)
+	}
+	return signer.(crypto.Ed25519Signer), addr
+}
+
+// Der Beweis muss gültig sein
+func _MustVerify(name string, proof *crypto.PeerLinkZKP, expected crypto.PeerLinkZKPStatement, context []byte) {
+	result, err := crypto.VerifyPeerLinkZKP(proof, expected, context)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %v", name, err))
+	}
+	if !result {
+		panic(fmt.Sprintf("%s: valid proof rejected", name))
+	}
+	fmt.Println(name, "OK")
+}
+
+// Der Beweis muss abgelehnt werden
+func _MustReject(name string, proof *crypto.PeerLinkZKP, expected crypto.PeerLinkZKPStatement, context []byte) {
+	result, err := crypto.VerifyPeerLinkZKP(proof, expected, context)
+	if result {
+		panic(fmt.Sprintf("%s: invalid proof accepted", name))
+	}
+	fmt.Println(name, "rejected:", err)
+}
+
+func main() {
+	context := []byte("verifier challenge")
+
+	// Zwei Adressen desselben Betreibers
+	first, firstAddr := _NewEd25519Signer()
+	second, secondAddr := _NewEd25519Signer()
+	_, foreignAddr := _NewEd25519Signer()
+
+	sameOperator, err := crypto.ProveSameOperator(first, second, context)
+	if err != nil {
+		panic(err)
+	}
+
+	expected := crypto.PeerLinkZKPStatement{Type: crypto.PeerLinkZKP_SameOperator, Prover: firstAddr, Linked: secondAddr}
+	_MustVerify("SameOperator", sameOperator, expected, context)
+	_MustReject("SameOperator wrong context", sameOperator, expected, []byte("other challenge"))
+	_MustReject("SameOperator wrong linked address", sameOperator, crypto.PeerLinkZKPStatement{Type: crypto.PeerLinkZKP_SameOperator, Prover: firstAddr, Linked: foreignAddr}, context)
+	_MustReject("SameOperator wrong type", sameOperator, crypto.PeerLinkZKPStatement{Type: crypto.PeerLinkZKP_PeerLink, Prover: firstAddr, Linked: secondAddr}, context)
+
+	// Beide Seiten eines Links leiten dasselbe Geheimnis ab, der Prüfer kennt das Commitment aus eigener Quelle
+	alice, aliceAddr := _NewEd25519Signer()
+	bob, bobAddr := _NewEd25519Signer()
+	mallory, malloryAddr := _NewEd25519Signer()
+
+	aliceLink, err := crypto.DerivePeerLinkSecret(alice, bobAddr)
+	if err != nil {
+		panic(err)
+	}
+	bobLink, err := crypto.DerivePeerLinkSecret(bob, aliceAddr)
+	if err != nil {
+		panic(err)
+	}
+	if string(aliceLink.Commitment()) != string(bobLink.Commitment()) {
+		panic("peer link secrets differ")
+	}
+
+	peerLink, err := crypto.ProvePeerLink(alice, aliceLink, context)
+	if err != nil {
+		panic(err)
+	}
+
+	expected = crypto.PeerLinkZKPStatement{Type: crypto.PeerLinkZKP_PeerLink, Prover: aliceAddr, Linked: bobAddr, LinkCommitment: bobLink.Commitment()}
+	_MustVerify("PeerLink", peerLink, expected, context)
+	_MustReject("PeerLink wrong context", peerLink, expected, []byte("other challenge"))
+	_MustReject("PeerLink missing commitment", peerLink, crypto.PeerLinkZKPStatement{Type: crypto.PeerLinkZKP_PeerLink, Prover: aliceAddr, Linked: bobAddr}, context)
+
+	// Mallory beweist Wissen über einen eigenen Link und gibt ihn als Link zwischen Alice und Bob aus
+	malloryLink, err := crypto.DerivePeerLinkSecret(mallory, bobAddr)
+	if err != nil {
+		panic(err)
+	}
+	forged, err := crypto.ProvePeerLink(mallory, malloryLink, context)
+	if err != nil {
+		panic(err)
+	}
+	_MustReject("PeerLink foreign commitment", forged, expected, context)
+	_MustReject("PeerLink foreign prover", forged, crypto.PeerLinkZKPStatement{Type: crypto.PeerLinkZKP_PeerLink, Prover: malloryAddr, Linked: bobAddr, LinkCommitment: bobLink.Commitment()}, context)
+
+	// Ein manipulierter Beweis wird nach der Übertragung abgelehnt
+	bytedProof, err := peerLink.ToByteSlice()
+	if err != nil {
+		panic(err)
+	}
+	decoded, err := crypto.PeerLinkZKPFromByteSlice(bytedProof)
+	if err != nil {
+		panic(err)
+	}
+	_MustVerify("PeerLink decoded", decoded, expected, context)
+	decoded.Responses[0][0] ^= 0x01
+	_MustReject("PeerLink tampered response", decoded, expected, context)
+}